@@ -0,0 +1,280 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/basebytes/component/database/rdb"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      func(tx *rdb.Instance) error
+	Down    func(tx *rdb.Instance) error
+}
+
+type Status struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+}
+
+type Migrator struct {
+	Table       string
+	LockTimeout time.Duration
+	StaleLock   time.Duration // locks not refreshed within StaleLock are taken over, <= 0 never takes over
+
+	ins        *rdb.Instance
+	migrations []*Migration
+	owner      string
+}
+
+type appliedMigration struct {
+	Version   int64     `gorm:"column:version;primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"column:name;size:255"`
+	AppliedAt time.Time `gorm:"column:applied_at"`
+}
+
+type migrationLock struct {
+	Id       int       `gorm:"column:id;primaryKey;autoIncrement:false"`
+	Owner    string    `gorm:"column:owner;size:255"`
+	LockedAt time.Time `gorm:"column:locked_at"`
+}
+
+func New(ins *rdb.Instance, migrations ...*Migration) (m *Migrator, err error) {
+	if ins == nil {
+		return nil, errors.New("migration instance is nil")
+	}
+	sorted := append([]*Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+	for i, migration := range sorted {
+		switch {
+		case migration.Version <= 0:
+			err = fmt.Errorf("migration %s has invalid version %d", migration.Name, migration.Version)
+		case migration.Up == nil:
+			err = fmt.Errorf("migration %d_%s has no up step", migration.Version, migration.Name)
+		case i > 0 && sorted[i-1].Version == migration.Version:
+			err = fmt.Errorf("duplicate migration version %d", migration.Version)
+		}
+		if err != nil {
+			return
+		}
+	}
+	host, _ := os.Hostname()
+	m = &Migrator{
+		Table:       defaultTable,
+		LockTimeout: defaultLockTimeout,
+		StaleLock:   defaultStaleLock,
+		ins:         ins,
+		migrations:  sorted,
+		owner:       fmt.Sprintf("%s-%d-%d", host, os.Getpid(), time.Now().UnixNano()),
+	}
+	return
+}
+
+func (m *Migrator) Up(ctx context.Context) (applied []int64, err error) {
+	err = m.locked(ctx, func(ins *rdb.Instance, done map[int64]*appliedMigration) (err error) {
+		for _, migration := range m.migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+			if err = m.refresh(ins.DB()); err != nil {
+				return
+			}
+			if err = ins.Tx(func(tx *rdb.Instance) (err error) {
+				if err = migration.Up(tx); err == nil {
+					err = tx.DB().Table(m.Table).Create(&appliedMigration{
+						Version:   migration.Version,
+						Name:      migration.Name,
+						AppliedAt: time.Now(),
+					}).Error
+				}
+				return
+			}); err != nil {
+				return fmt.Errorf("migration %d_%s up failed :%w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration.Version)
+		}
+		return
+	})
+	return
+}
+
+func (m *Migrator) Down(ctx context.Context, steps int) (reverted []int64, err error) {
+	err = m.locked(ctx, func(ins *rdb.Instance, done map[int64]*appliedMigration) (err error) {
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == nil {
+				return fmt.Errorf("migration %d_%s has no down step", migration.Version, migration.Name)
+			}
+			if err = m.refresh(ins.DB()); err != nil {
+				return
+			}
+			if err = ins.Tx(func(tx *rdb.Instance) (err error) {
+				if err = migration.Down(tx); err == nil {
+					err = tx.DB().Table(m.Table).Where(clause.Eq{Column: clause.Column{Name: "version"}, Value: migration.Version}).Delete(&appliedMigration{}).Error
+				}
+				return
+			}); err != nil {
+				return fmt.Errorf("migration %d_%s down failed :%w", migration.Version, migration.Name, err)
+			}
+			reverted = append(reverted, migration.Version)
+		}
+		return
+	})
+	return
+}
+
+func (m *Migrator) Status(ctx context.Context) (status []*Status, err error) {
+	ins := m.ins.WithContext(ctx)
+	if err = m.prepare(ins.DB()); err != nil {
+		return
+	}
+	var done map[int64]*appliedMigration
+	if done, err = m.applied(ins.DB()); err != nil {
+		return
+	}
+	for _, migration := range m.migrations {
+		s := &Status{Version: migration.Version, Name: migration.Name}
+		if applied, ok := done[migration.Version]; ok {
+			s.Applied, s.AppliedAt = true, &applied.AppliedAt
+			delete(done, migration.Version)
+		}
+		status = append(status, s)
+	}
+	for _, applied := range done {
+		status = append(status, &Status{Version: applied.Version, Name: applied.Name, Applied: true, AppliedAt: &applied.AppliedAt})
+	}
+	sort.Slice(status, func(i, j int) bool {
+		return status[i].Version < status[j].Version
+	})
+	return
+}
+
+func (m *Migrator) locked(ctx context.Context, fn func(ins *rdb.Instance, done map[int64]*appliedMigration) error) (err error) {
+	ins := m.ins.WithContext(ctx)
+	db := ins.DB()
+	if err = m.prepare(db); err != nil {
+		return
+	}
+	if err = m.lock(ctx, db); err != nil {
+		return
+	}
+	defer func() {
+		err = errors.Join(err, m.unlock(m.ins.DB()))
+	}()
+	if m.StaleLock > 0 {
+		stop := make(chan struct{})
+		defer close(stop)
+		go m.heartbeat(m.ins.DB(), stop)
+	}
+	var done map[int64]*appliedMigration
+	if done, err = m.applied(db); err == nil {
+		err = fn(ins, done)
+	}
+	return
+}
+
+func (m *Migrator) prepare(db *gorm.DB) (err error) {
+	for _, table := range []struct {
+		name  string
+		model any
+	}{{m.Table, &appliedMigration{}}, {m.lockTable(), &migrationLock{}}} {
+		if err = db.Table(table.name).AutoMigrate(table.model); err != nil && db.Migrator().HasTable(table.name) {
+			err = nil
+		}
+		if err != nil {
+			return fmt.Errorf("create migration table %s failed :%w", table.name, err)
+		}
+	}
+	return
+}
+
+func (m *Migrator) applied(db *gorm.DB) (done map[int64]*appliedMigration, err error) {
+	var rows []*appliedMigration
+	if err = db.Table(m.Table).Find(&rows).Error; err == nil {
+		done = make(map[int64]*appliedMigration, len(rows))
+		for _, row := range rows {
+			done[row.Version] = row
+		}
+	}
+	return
+}
+
+func (m *Migrator) lock(ctx context.Context, db *gorm.DB) (err error) {
+	deadline := time.Now().Add(m.LockTimeout)
+	for {
+		now := time.Now()
+		if m.StaleLock > 0 {
+			if err = db.Table(m.lockTable()).Where(clause.Lt{Column: clause.Column{Name: "locked_at"}, Value: now.Add(-m.StaleLock)}).Delete(&migrationLock{}).Error; err != nil {
+				return
+			}
+		}
+		res := db.Table(m.lockTable()).Clauses(clause.OnConflict{DoNothing: true}).Create(&migrationLock{Id: 1, Owner: m.owner, LockedAt: now})
+		if err = res.Error; err != nil || res.RowsAffected == 1 {
+			return
+		}
+		if now.After(deadline) {
+			return lockedErr
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(lockRetryInterval):
+		}
+	}
+}
+
+func (m *Migrator) refresh(db *gorm.DB) error {
+	res := db.Table(m.lockTable()).Where(clause.Eq{Column: clause.Column{Name: "owner"}, Value: m.owner}).Update("locked_at", time.Now())
+	if res.Error == nil && res.RowsAffected == 0 {
+		return lostLockErr
+	}
+	return res.Error
+}
+
+func (m *Migrator) heartbeat(db *gorm.DB, stop <-chan struct{}) {
+	ticker := time.NewTicker(m.StaleLock / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			_ = m.refresh(db)
+		}
+	}
+}
+
+func (m *Migrator) unlock(db *gorm.DB) error {
+	return db.Table(m.lockTable()).Where(clause.Eq{Column: clause.Column{Name: "owner"}, Value: m.owner}).Delete(&migrationLock{}).Error
+}
+
+func (m *Migrator) lockTable() string {
+	return m.Table + "_lock"
+}
+
+var (
+	lockedErr   = errors.New("migration lock is held by another process")
+	lostLockErr = errors.New("migration lock was taken over by another process")
+)
+
+const (
+	defaultTable       = "schema_migrations"
+	defaultLockTimeout = time.Minute
+	defaultStaleLock   = 15 * time.Minute
+	lockRetryInterval  = time.Second
+)
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS `biz_dict` (
+    `id`          BIGINT        NOT NULL AUTO_INCREMENT,
+    `category`    VARCHAR(64)   NOT NULL,
+    `key`         VARCHAR(128)  NOT NULL,
+    `value`       VARCHAR(1024) NOT NULL DEFAULT '',
+    `mapping_key` VARCHAR(128)  NOT NULL DEFAULT '',
+    `seq`         INT           NULL,
+    `status`      INT           NOT NULL DEFAULT 0,
+    `create_time` DATETIME      NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `update_time` DATETIME      NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_biz_dict_category_key` (`category`, `key`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS `server_config` (
+    `id`           BIGINT       NOT NULL AUTO_INCREMENT,
+    `server`       VARCHAR(128) NOT NULL,
+    `type`         VARCHAR(64)  NOT NULL,
+    `content`      MEDIUMBLOB   NULL,
+    `last_content` MEDIUMBLOB   NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_server_config_server_type` (`server`, `type`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS "biz_dict" (
+    "id"          BIGSERIAL PRIMARY KEY,
+    "category"    VARCHAR(64)   NOT NULL,
+    "key"         VARCHAR(128)  NOT NULL,
+    "value"       VARCHAR(1024) NOT NULL DEFAULT '',
+    "mapping_key" VARCHAR(128)  NOT NULL DEFAULT '',
+    "seq"         INTEGER       NULL,
+    "status"      INTEGER       NOT NULL DEFAULT 0,
+    "create_time" TIMESTAMP     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "update_time" TIMESTAMP     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS "idx_biz_dict_category_key" ON "biz_dict" ("category", "key");
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS "server_config" (
+    "id"           BIGSERIAL PRIMARY KEY,
+    "server"       VARCHAR(128) NOT NULL,
+    "type"         VARCHAR(64)  NOT NULL,
+    "content"      BYTEA        NULL,
+    "last_content" BYTEA        NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "uk_server_config_server_type" ON "server_config" ("server", "type");
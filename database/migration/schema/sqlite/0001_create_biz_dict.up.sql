@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS `biz_dict` (
+    `id`          INTEGER PRIMARY KEY AUTOINCREMENT,
+    `category`    TEXT     NOT NULL,
+    `key`         TEXT     NOT NULL,
+    `value`       TEXT     NOT NULL DEFAULT '',
+    `mapping_key` TEXT     NOT NULL DEFAULT '',
+    `seq`         INTEGER  NULL,
+    `status`      INTEGER  NOT NULL DEFAULT 0,
+    `create_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `update_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS `idx_biz_dict_category_key` ON `biz_dict` (`category`, `key`);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS `server_config` (
+    `id`           INTEGER PRIMARY KEY AUTOINCREMENT,
+    `server`       TEXT NOT NULL,
+    `type`         TEXT NOT NULL,
+    `content`      BLOB NULL,
+    `last_content` BLOB NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `uk_server_config_server_type` ON `server_config` (`server`, `type`);
@@ -0,0 +1,95 @@
+package migration
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/basebytes/component/database/rdb"
+)
+
+//go:embed schema
+var schemaFS embed.FS
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([A-Za-z0-9_]+)\.(up|down)\.sql$`)
+
+func Builtin(dialect string) ([]*Migration, error) {
+	return FromFS(schemaFS, path.Join("schema", dialect))
+}
+
+func FromFS(fsys fs.FS, dir string) (migrations []*Migration, err error) {
+	var entries []fs.DirEntry
+	if entries, err = fs.ReadDir(fsys, dir); err != nil {
+		return
+	}
+	index := make(map[int64]*Migration)
+	for _, entry := range entries {
+		matches := fileNamePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || matches == nil {
+			continue
+		}
+		version, _ := strconv.ParseInt(matches[1], 10, 64)
+		migration, ok := index[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: matches[2]}
+			index[version] = migration
+			migrations = append(migrations, migration)
+		} else if migration.Name != matches[2] {
+			return nil, fmt.Errorf("migration version %d has conflicting names %s and %s", version, migration.Name, matches[2])
+		}
+		var content []byte
+		if content, err = fs.ReadFile(fsys, path.Join(dir, entry.Name())); err != nil {
+			return
+		}
+		step := sqlStep(splitStatements(string(content)))
+		if matches[3] == "up" {
+			migration.Up = step
+		} else {
+			migration.Down = step
+		}
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return
+}
+
+func sqlStep(statements []string) func(tx *rdb.Instance) error {
+	return func(tx *rdb.Instance) (err error) {
+		for _, statement := range statements {
+			if err = tx.DB().Exec(statement).Error; err != nil {
+				return
+			}
+		}
+		return
+	}
+}
+
+func splitStatements(content string) (statements []string) {
+	var (
+		builder strings.Builder
+		scanner = bufio.NewScanner(strings.NewReader(content))
+	)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "--") {
+			continue
+		}
+		builder.WriteString(scanner.Text())
+		builder.WriteByte('\n')
+		if strings.HasSuffix(line, ";") {
+			statements = append(statements, strings.TrimSpace(builder.String()))
+			builder.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(builder.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return
+}
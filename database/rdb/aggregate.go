@@ -0,0 +1,200 @@
+package rdb
+
+import (
+	"database/sql"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BucketUnit = string
+
+const (
+	BucketDay   BucketUnit = "day"
+	BucketWeek  BucketUnit = "week"
+	BucketMonth BucketUnit = "month"
+)
+
+type Aggregation struct {
+	fn    string
+	field string
+	unit  BucketUnit
+	alias string
+}
+
+func AggField(field string) Aggregation {
+	return Aggregation{field: field}
+}
+
+func Sum(field, alias string) Aggregation {
+	return Aggregation{fn: "SUM", field: field, alias: alias}
+}
+
+func Avg(field, alias string) Aggregation {
+	return Aggregation{fn: "AVG", field: field, alias: alias}
+}
+
+func Min(field, alias string) Aggregation {
+	return Aggregation{fn: "MIN", field: field, alias: alias}
+}
+
+func Max(field, alias string) Aggregation {
+	return Aggregation{fn: "MAX", field: field, alias: alias}
+}
+
+func CountAll(alias string) Aggregation {
+	return Aggregation{fn: "COUNT", alias: alias}
+}
+
+func CountDistinct(field, alias string) Aggregation {
+	return Aggregation{fn: "COUNT DISTINCT", field: field, alias: alias}
+}
+
+func DateBucket(field string, unit BucketUnit, alias string) Aggregation {
+	return Aggregation{fn: "BUCKET", field: field, unit: unit, alias: alias}
+}
+
+func Aggregate(items ...Aggregation) Condition {
+	return func(db *gorm.DB) *gorm.DB {
+		exprs := make([]clause.Expression, 0, len(items))
+		for _, item := range items {
+			expr, ok := item.selectExpr(db)
+			if !ok {
+				return db
+			}
+			exprs = append(exprs, expr)
+		}
+		if len(exprs) > 0 {
+			db = db.Clauses(clause.Select{Expression: clause.CommaExpression{Exprs: exprs}})
+		}
+		return db
+	}
+}
+
+func GroupBy(items ...Aggregation) Condition {
+	return func(db *gorm.DB) *gorm.DB {
+		columns := make([]clause.Column, 0, len(items))
+		for _, item := range items {
+			expr, ok := item.expr(db)
+			if !ok {
+				return db
+			}
+			columns = append(columns, clause.Column{Name: buildSQL(db, expr), Raw: true})
+		}
+		if len(columns) > 0 {
+			db = db.Clauses(clause.GroupBy{Columns: columns})
+		}
+		return db
+	}
+}
+
+func Having(item Aggregation, op OpType, value any) Condition {
+	return func(db *gorm.DB) *gorm.DB {
+		expr, ok := item.expr(db)
+		if !ok {
+			return db
+		}
+		switch op {
+		case LT, GT, LTE, GTE, "=", "!=":
+			db = db.Clauses(clause.GroupBy{Having: []clause.Expression{clause.Expr{SQL: fmt.Sprintf("? %s ?", op), Vars: []any{expr, value}}}})
+		default:
+			_ = db.AddError(fmt.Errorf("unSupport having op %s", op))
+		}
+		return db
+	}
+}
+
+func (a Aggregation) selectExpr(db *gorm.DB) (clause.Expression, bool) {
+	expr, ok := a.expr(db)
+	if !ok || a.alias == "" {
+		return expr, ok
+	}
+	if !ValidColumn(a.alias) {
+		_ = db.AddError(fmt.Errorf("invalid alias %q", a.alias))
+		return nil, false
+	}
+	return clause.Expr{SQL: "? AS ?", Vars: []any{expr, clause.Column{Name: a.alias}}}, true
+}
+
+func (a Aggregation) expr(db *gorm.DB) (expr clause.Expression, ok bool) {
+	if a.fn == "COUNT" {
+		return clause.Expr{SQL: "COUNT(*)"}, true
+	}
+	var col clause.Column
+	if col, ok = column(db, a.field); !ok {
+		return
+	}
+	switch a.fn {
+	case "":
+		expr = clause.Expr{SQL: "?", Vars: []any{col}}
+	case "BUCKET":
+		var format string
+		if format, ok = bucketFormats[dialect(db)][a.unit]; !ok {
+			_ = db.AddError(fmt.Errorf("unSupport date bucket %s for dialect %s", a.unit, dialect(db)))
+			return
+		}
+		expr = clause.Expr{SQL: format, Vars: []any{col}}
+	case "COUNT DISTINCT":
+		expr = clause.Expr{SQL: "COUNT(DISTINCT ?)", Vars: []any{col}}
+	default:
+		expr = clause.Expr{SQL: a.fn + "(?)", Vars: []any{col}}
+	}
+	return
+}
+
+func buildSQL(db *gorm.DB, expr clause.Expression) string {
+	stmt := &gorm.Statement{DB: db, Clauses: map[string]clause.Clause{}}
+	expr.Build(stmt)
+	return stmt.SQL.String()
+}
+
+func (ins *Instance) Aggregate(table Data, result any, conditions ...Condition) error {
+	return ins.DB().Model(table).Where(table).Scopes(conditions...).Scan(result).Error
+}
+
+func (ins *Instance) Sum(table Data, field string, conditions ...Condition) (float64, error) {
+	return ins.aggregateFloat(table, Sum(field, ""), conditions...)
+}
+
+func (ins *Instance) Avg(table Data, field string, conditions ...Condition) (float64, error) {
+	return ins.aggregateFloat(table, Avg(field, ""), conditions...)
+}
+
+func (ins *Instance) Min(table Data, field string, result any, conditions ...Condition) error {
+	return ins.Aggregate(table, result, withConditions(conditions, Aggregate(Min(field, "")))...)
+}
+
+func (ins *Instance) Max(table Data, field string, result any, conditions ...Condition) error {
+	return ins.Aggregate(table, result, withConditions(conditions, Aggregate(Max(field, "")))...)
+}
+
+func (ins *Instance) aggregateFloat(table Data, item Aggregation, conditions ...Condition) (value float64, err error) {
+	var result sql.NullFloat64
+	if err = ins.Aggregate(table, &result, withConditions(conditions, Aggregate(item))...); err == nil {
+		value = result.Float64
+	}
+	return
+}
+
+func withConditions(conditions []Condition, extra ...Condition) []Condition {
+	return append(append(make([]Condition, 0, len(conditions)+len(extra)), conditions...), extra...)
+}
+
+var bucketFormats = map[string]map[BucketUnit]string{
+	driverMysql: {
+		BucketDay:   "DATE_FORMAT(?,'%Y-%m-%d')",
+		BucketWeek:  "DATE_FORMAT(?,'%x-%v')",
+		BucketMonth: "DATE_FORMAT(?,'%Y-%m')",
+	},
+	driverSqlite: {
+		BucketDay:   "strftime('%Y-%m-%d',?)",
+		BucketWeek:  "strftime('%Y-%W',?)",
+		BucketMonth: "strftime('%Y-%m',?)",
+	},
+	driverPostgres: {
+		BucketDay:   "to_char(?,'YYYY-MM-DD')",
+		BucketWeek:  "to_char(?,'IYYY-IW')",
+		BucketMonth: "to_char(?,'YYYY-MM')",
+	},
+}
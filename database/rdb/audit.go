@@ -0,0 +1,89 @@
+package rdb
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type operatorKey struct{}
+
+func WithOperator(ctx context.Context, operator any) context.Context {
+	return context.WithValue(ctx, operatorKey{}, operator)
+}
+
+func OperatorOf(ctx context.Context) (operator any, ok bool) {
+	if ctx != nil {
+		operator = ctx.Value(operatorKey{})
+	}
+	return operator, operator != nil
+}
+
+type Audit struct {
+	CreatedBy string
+	UpdatedBy string
+	CreatedAt string
+	UpdatedAt string
+}
+
+func (p *Audit) Name() string {
+	return auditCallbackName
+}
+
+func (p *Audit) Initialize(db *gorm.DB) error {
+	if p.CreatedBy == "" {
+		p.CreatedBy = defaultAuditCreatedBy
+	}
+	if p.UpdatedBy == "" {
+		p.UpdatedBy = defaultAuditUpdatedBy
+	}
+	if p.CreatedAt == "" {
+		p.CreatedAt = defaultAuditCreatedAt
+	}
+	if p.UpdatedAt == "" {
+		p.UpdatedAt = defaultAuditUpdatedAt
+	}
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register(auditCallbackName, p.create),
+		cb.Update().Before("gorm:update").Register(auditCallbackName, p.update),
+	)
+}
+
+func (p *Audit) create(db *gorm.DB) {
+	if db.Error != nil || db.Statement.SkipHooks {
+		return
+	}
+	now := db.NowFunc()
+	p.stamp(db, p.CreatedAt, now, true)
+	p.stamp(db, p.UpdatedAt, now, true)
+	if operator, ok := OperatorOf(db.Statement.Context); ok {
+		p.stamp(db, p.CreatedBy, operator, true)
+		p.stamp(db, p.UpdatedBy, operator, true)
+	}
+}
+
+func (p *Audit) update(db *gorm.DB) {
+	if db.Error != nil || db.Statement.SkipHooks {
+		return
+	}
+	p.stamp(db, p.UpdatedAt, db.NowFunc(), false)
+	if operator, ok := OperatorOf(db.Statement.Context); ok {
+		p.stamp(db, p.UpdatedBy, operator, false)
+	}
+}
+
+func (p *Audit) stamp(db *gorm.DB, column string, value any, create bool) {
+	if field := lookUpColumn(db.Statement, column); field != nil && (create && field.Creatable || !create && field.Updatable) {
+		db.Statement.SetColumn(field.DBName, value, true)
+	}
+}
+
+const (
+	auditCallbackName     = "rdb:audit"
+	defaultAuditCreatedBy = "create_by"
+	defaultAuditUpdatedBy = "update_by"
+	defaultAuditCreatedAt = "create_time"
+	defaultAuditUpdatedAt = "update_time"
+)
@@ -0,0 +1,98 @@
+package rdb
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+type Checkpoint struct {
+	Key     any   `json:"key,omitempty"`
+	Batches int   `json:"batches"`
+	Rows    int64 `json:"rows"`
+}
+
+func (ins *Instance) Rows(table Data, conditions ...Condition) (*sql.Rows, error) {
+	return ins.DB().Model(table).Where(table).Scopes(conditions...).Rows()
+}
+
+func (ins *Instance) Each(table Data, fn func(row Data) error, conditions ...Condition) (err error) {
+	var rows *sql.Rows
+	if rows, err = ins.Rows(table, conditions...); err != nil {
+		return
+	}
+	defer rows.Close()
+	db := ins.DB()
+	for rows.Next() {
+		row := newRow(table)
+		if err = db.ScanRows(rows, row); err == nil {
+			err = fn(row)
+		}
+		if err != nil {
+			return
+		}
+	}
+	return rows.Err()
+}
+
+func (ins *Instance) FindInBatches(table Data, results any, size int, cp *Checkpoint, fn func(cp *Checkpoint) error, conditions ...Condition) (err error) {
+	if size <= 0 {
+		return fmt.Errorf("invalid batch size %d", size)
+	}
+	rv := reflect.ValueOf(results)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("batch results must be a slice pointer, got %T", results)
+	}
+	var pk *schema.Field
+	if pk, err = primaryField(ins.DB(), table); err != nil {
+		return
+	}
+	if cp == nil {
+		cp = &Checkpoint{}
+	}
+	items := rv.Elem()
+	for {
+		items.Set(items.Slice(0, 0))
+		db := ins.DB().Model(table).Where(table).Scopes(conditions...)
+		if cp.Key != nil {
+			db = db.Where(clause.Gt{Column: clause.Column{Table: clause.CurrentTable, Name: pk.DBName}, Value: cp.Key})
+		}
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: pk.DBName}}).Limit(size).Find(results)
+		if err = db.Error; err != nil || items.Len() == 0 {
+			return
+		}
+		next := *cp
+		next.Key, _ = pk.ValueOf(db.Statement.Context, reflect.Indirect(items.Index(items.Len()-1)))
+		next.Batches++
+		next.Rows += int64(items.Len())
+		if err = fn(&next); err != nil {
+			return
+		}
+		*cp = next
+		if items.Len() < size {
+			return
+		}
+	}
+}
+
+func primaryField(db *gorm.DB, table Data) (field *schema.Field, err error) {
+	stmt := &gorm.Statement{DB: db}
+	if err = stmt.Parse(table); err == nil {
+		if field = stmt.Schema.PrioritizedPrimaryField; field == nil {
+			err = fmt.Errorf("table %s has no primary key", stmt.Schema.Table)
+		}
+	}
+	return
+}
+
+func newRow(table Data) Data {
+	typ := reflect.TypeOf(table)
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return reflect.New(typ).Interface().(Data)
+}
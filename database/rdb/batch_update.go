@@ -0,0 +1,195 @@
+package rdb
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+type updateGroup struct {
+	schema  *schema.Schema
+	pk      *schema.Field
+	columns []*schema.Field
+	rows    []*updateRow
+}
+
+type updateRow struct {
+	data   Data
+	key    any
+	values []any
+}
+
+func (ins *Instance) batchUpdates(table []Data, refresh bool) (err error) {
+	if len(table) == 0 {
+		return
+	}
+	db := ins.DB()
+	var groups []*updateGroup
+	if groups, err = groupUpdates(db, table); err != nil {
+		return
+	}
+	return ins.Transaction(func(tx *gorm.DB) (err error) {
+		for _, group := range groups {
+			if err = group.update(tx); err == nil && refresh {
+				err = group.refresh(tx)
+			}
+			if err != nil {
+				break
+			}
+		}
+		return
+	})
+}
+
+func groupUpdates(db *gorm.DB, table []Data) (groups []*updateGroup, err error) {
+	var (
+		index = make(map[string]*updateGroup)
+		now   = db.NowFunc()
+	)
+	for _, data := range table {
+		stmt := &gorm.Statement{DB: db}
+		if err = stmt.Parse(data); err != nil {
+			return
+		}
+		s := stmt.Schema
+		pk := s.PrioritizedPrimaryField
+		if pk == nil {
+			return nil, fmt.Errorf("table %s has no primary key", s.Table)
+		}
+		rv := reflect.Indirect(reflect.ValueOf(data))
+		key, zero := pk.ValueOf(stmt.Context, rv)
+		if zero {
+			return nil, fmt.Errorf("table %s update row without primary key", s.Table)
+		}
+		row := &updateRow{data: data, key: key}
+		var (
+			columns []*schema.Field
+			names   = []string{s.Table}
+		)
+		for _, field := range s.Fields {
+			if field.DBName == "" || field.PrimaryKey || !field.Updatable {
+				continue
+			}
+			value, zero := field.ValueOf(stmt.Context, rv)
+			if field.AutoUpdateTime > 0 {
+				value, zero = autoUpdateTime(field, now), false
+			}
+			if zero {
+				continue
+			}
+			columns = append(columns, field)
+			names = append(names, field.DBName)
+			row.values = append(row.values, value)
+		}
+		if len(columns) == 0 {
+			continue
+		}
+		sort.Strings(names[1:])
+		groupKey := strings.Join(names, ",")
+		group, ok := index[groupKey]
+		if !ok {
+			group = &updateGroup{schema: s, pk: pk, columns: columns}
+			index[groupKey] = group
+			groups = append(groups, group)
+		}
+		group.rows = append(group.rows, row.align(columns, group.columns))
+	}
+	return
+}
+
+func (r *updateRow) align(from, to []*schema.Field) *updateRow {
+	values := make([]any, len(to))
+	for i, field := range to {
+		for j, f := range from {
+			if f.DBName == field.DBName {
+				values[i] = r.values[j]
+				break
+			}
+		}
+	}
+	r.values = values
+	return r
+}
+
+func (g *updateGroup) update(tx *gorm.DB) (err error) {
+	size := bulkSize(dialect(tx), 2*len(g.columns)+1)
+	pkColumn := clause.Column{Name: g.pk.DBName}
+	for start := 0; start < len(g.rows); start += size {
+		end := start + size
+		if end > len(g.rows) {
+			end = len(g.rows)
+		}
+		rows := g.rows[start:end]
+		keys := make([]any, 0, len(rows))
+		for _, row := range rows {
+			keys = append(keys, row.key)
+		}
+		values := make(map[string]any, len(g.columns))
+		for i, field := range g.columns {
+			values[field.DBName] = g.caseExpr(tx, pkColumn, field, rows, i)
+		}
+		res := tx.Model(reflect.New(g.schema.ModelType).Interface()).Where(clause.IN{Column: pkColumn, Values: keys}).Updates(values)
+		if err = res.Error; err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (g *updateGroup) caseExpr(tx *gorm.DB, pkColumn clause.Column, field *schema.Field, rows []*updateRow, index int) clause.Expr {
+	then := "?"
+	if dialect(tx) == driverPostgres {
+		then = fmt.Sprintf("CAST(? AS %s)", tx.Dialector.DataTypeOf(field))
+	}
+	var sql strings.Builder
+	vars := make([]any, 0, 2*len(rows)+1)
+	sql.WriteString("CASE ?")
+	vars = append(vars, pkColumn)
+	for _, row := range rows {
+		sql.WriteString(" WHEN ? THEN ")
+		sql.WriteString(then)
+		vars = append(vars, row.key, row.values[index])
+	}
+	sql.WriteString(" END")
+	return clause.Expr{SQL: sql.String(), Vars: vars}
+}
+
+func (g *updateGroup) refresh(tx *gorm.DB) (err error) {
+	keys := make([]any, 0, len(g.rows))
+	rows := make(map[any]*updateRow, len(g.rows))
+	for _, row := range g.rows {
+		keys = append(keys, row.key)
+		rows[row.key] = row
+	}
+	results := reflect.New(reflect.SliceOf(reflect.PointerTo(g.schema.ModelType)))
+	if err = tx.Model(reflect.New(g.schema.ModelType).Interface()).Where(clause.IN{Column: clause.Column{Name: g.pk.DBName}, Values: keys}).Find(results.Interface()).Error; err != nil {
+		return
+	}
+	results = results.Elem()
+	for i := 0; i < results.Len(); i++ {
+		fresh := results.Index(i).Elem()
+		key, _ := g.pk.ValueOf(tx.Statement.Context, fresh)
+		if row, ok := rows[key]; ok {
+			reflect.Indirect(reflect.ValueOf(row.data)).Set(fresh)
+		}
+	}
+	return
+}
+
+func autoUpdateTime(field *schema.Field, now time.Time) any {
+	switch field.AutoUpdateTime {
+	case schema.UnixNanosecond:
+		return now.UnixNano()
+	case schema.UnixMillisecond:
+		return now.UnixMilli()
+	case schema.UnixSecond:
+		return now.Unix()
+	}
+	return now
+}
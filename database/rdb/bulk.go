@@ -0,0 +1,221 @@
+package rdb
+
+import (
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+type BulkResult struct {
+	Inserted int64 `json:"inserted"`
+	Updated  int64 `json:"updated"`
+	Ignored  int64 `json:"ignored"`
+}
+
+func (ins *Instance) BulkCreate(values any, size int) (BulkResult, error) {
+	return ins.bulk(values, size, nil, nil, false)
+}
+
+func (ins *Instance) BulkCreateIgnoreConflicts(conflicts []string, values any, size int) (BulkResult, error) {
+	return ins.bulk(values, size, conflicts, nil, true)
+}
+
+func (ins *Instance) BulkUpsert(conflicts, updates []string, values any, size int) (BulkResult, error) {
+	return ins.bulk(values, size, conflicts, updates, false)
+}
+
+func (ins *Instance) bulk(values any, size int, conflicts, updates []string, ignore bool) (result BulkResult, err error) {
+	items := reflect.Indirect(reflect.ValueOf(values))
+	if items.Kind() != reflect.Slice {
+		return result, fmt.Errorf("bulk values must be a slice, got %T", values)
+	}
+	if items.Len() == 0 {
+		return
+	}
+	db := ins.DB()
+	stmt := &gorm.Statement{DB: db}
+	if err = stmt.Parse(values); err != nil {
+		return
+	}
+	if size <= 0 {
+		size = bulkSize(dialect(db), len(stmt.Schema.DBNames))
+	}
+	upsert := ignore || len(updates) > 0
+	if upsert && len(conflicts) == 0 {
+		conflicts = stmt.Schema.PrimaryFieldDBNames
+	}
+	var onConflict clause.OnConflict
+	if upsert {
+		if onConflict, err = conflictClause(stmt.Schema, conflicts, updates, ignore); err != nil {
+			return
+		}
+	}
+	err = ins.Transaction(func(tx *gorm.DB) (err error) {
+		for start := 0; start < items.Len(); start += size {
+			end := start + size
+			if end > items.Len() {
+				end = items.Len()
+			}
+			chunk, count := items.Slice(start, end), int64(end-start)
+			var existing int64
+			if upsert {
+				if existing, err = countConflicts(tx, stmt.Schema, conflicts, chunk); err != nil {
+					return
+				}
+			}
+			create := tx
+			if upsert {
+				create = create.Clauses(onConflict)
+			}
+			res := create.Create(chunk.Interface())
+			if err = res.Error; err != nil {
+				return
+			}
+			switch {
+			case ignore:
+				result.Inserted += count - existing
+				result.Ignored += existing
+			case len(updates) > 0:
+				result.Inserted += count - existing
+				result.Updated += existing
+			default:
+				result.Inserted += res.RowsAffected
+			}
+		}
+		return
+	})
+	if err != nil {
+		result = BulkResult{}
+	}
+	return
+}
+
+func conflictClause(s *schema.Schema, conflicts, updates []string, ignore bool) (onConflict clause.OnConflict, err error) {
+	if len(conflicts) == 0 {
+		return onConflict, fmt.Errorf("table %s conflict columns required", s.Table)
+	}
+	for _, name := range conflicts {
+		if s.LookUpField(name) == nil {
+			return onConflict, fmt.Errorf("conflict column %s not found in %s", name, s.Table)
+		}
+		onConflict.Columns = append(onConflict.Columns, clause.Column{Name: name})
+	}
+	if ignore {
+		onConflict.DoNothing = true
+	} else {
+		for _, name := range updates {
+			if s.LookUpField(name) == nil {
+				return onConflict, fmt.Errorf("update column %s not found in %s", name, s.Table)
+			}
+		}
+		onConflict.DoUpdates = clause.AssignmentColumns(updates)
+	}
+	return
+}
+
+func countConflicts(tx *gorm.DB, s *schema.Schema, conflicts []string, chunk reflect.Value) (count int64, err error) {
+	fields := make([]*schema.Field, 0, len(conflicts))
+	names := make([]string, 0, len(conflicts))
+	columns := make([]any, 0, len(conflicts))
+	for _, name := range conflicts {
+		field := s.LookUpField(name)
+		fields = append(fields, field)
+		names = append(names, field.DBName)
+		columns = append(columns, clause.Column{Name: field.DBName})
+	}
+	keys := make([]string, chunk.Len())
+	tuples := make([]any, 0, chunk.Len())
+	for i := 0; i < chunk.Len(); i++ {
+		if tuple, ok := conflictTuple(tx, fields, reflect.Indirect(chunk.Index(i))); ok {
+			keys[i] = tupleKey(tuple)
+			tuples = append(tuples, tuple)
+		}
+	}
+	if len(tuples) == 0 {
+		return
+	}
+	db := tx.Table(s.Table).Unscoped().Select(names)
+	if len(fields) == 1 {
+		values := make([]any, 0, len(tuples))
+		for _, tuple := range tuples {
+			values = append(values, tuple.([]any)[0])
+		}
+		db = db.Where(clause.IN{Column: columns[0], Values: values})
+	} else {
+		db = db.Where(clause.Expr{SQL: "? IN ?", Vars: []any{columns, tuples}})
+	}
+	rows := reflect.New(reflect.SliceOf(s.ModelType))
+	if err = db.Find(rows.Interface()).Error; err != nil {
+		return
+	}
+	seen := make(map[string]struct{}, rows.Elem().Len()+len(keys))
+	for i := 0; i < rows.Elem().Len(); i++ {
+		if tuple, ok := conflictTuple(tx, fields, rows.Elem().Index(i)); ok {
+			seen[tupleKey(tuple)] = struct{}{}
+		}
+	}
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if _, ok := seen[key]; ok {
+			count++
+		}
+		seen[key] = struct{}{}
+	}
+	return
+}
+
+func conflictTuple(tx *gorm.DB, fields []*schema.Field, item reflect.Value) (tuple []any, ok bool) {
+	tuple = make([]any, 0, len(fields))
+	for _, field := range fields {
+		value, zero := field.ValueOf(tx.Statement.Context, item)
+		if zero && field.AutoIncrement {
+			return nil, false
+		}
+		tuple = append(tuple, value)
+	}
+	return tuple, true
+}
+
+func tupleKey(tuple []any) string {
+	values := make([]any, 0, len(tuple))
+	for _, value := range tuple {
+		if rv := reflect.Indirect(reflect.ValueOf(value)); rv.IsValid() {
+			value = rv.Interface()
+		}
+		values = append(values, value)
+	}
+	return fmt.Sprintf("%#v", values)
+}
+
+func bulkSize(dialect string, columns int) (size int) {
+	if columns <= 0 {
+		columns = 1
+	}
+	variables, ok := maxBulkVariables[dialect]
+	if !ok {
+		variables = defaultBulkVariables
+	}
+	if size = variables / columns; size > maxBulkSize {
+		size = maxBulkSize
+	}
+	if size <= 0 {
+		size = 1
+	}
+	return
+}
+
+var maxBulkVariables = map[string]int{
+	driverMysql:    65535,
+	driverSqlite:   32766,
+	driverPostgres: 65535,
+}
+
+const (
+	defaultBulkVariables = 999
+	maxBulkSize          = 1000
+)
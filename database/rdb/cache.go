@@ -0,0 +1,378 @@
+package rdb
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/basebytes/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type CacheConfig struct {
+	TTL        *types.Duration            `json:"ttl,omitempty"`
+	Tables     map[string]*types.Duration `json:"tables,omitempty"`
+	MaxEntries int                        `json:"maxEntries,omitempty"`
+	MaxRows    int                        `json:"maxRows,omitempty"`
+}
+
+func (c *CacheConfig) Init() (err error) {
+	if c.MaxEntries <= 0 {
+		c.MaxEntries = defaultCacheMaxEntries
+	}
+	if c.MaxRows <= 0 {
+		c.MaxRows = defaultCacheMaxRows
+	}
+	if duration(c.TTL) < 0 {
+		return fmt.Errorf("invalid cache ttl %s", duration(c.TTL))
+	}
+	for table, ttl := range c.Tables {
+		if duration(ttl) < 0 {
+			return fmt.Errorf("invalid cache ttl %s of table %s", duration(ttl), table)
+		}
+	}
+	return
+}
+
+func (c *CacheConfig) clone() *CacheConfig {
+	if c == nil {
+		return nil
+	}
+	cfg := *c
+	cfg.TTL = cloneDuration(c.TTL)
+	if c.Tables != nil {
+		cfg.Tables = make(map[string]*types.Duration, len(c.Tables))
+		for table, ttl := range c.Tables {
+			cfg.Tables[table] = cloneDuration(ttl)
+		}
+	}
+	return &cfg
+}
+
+type CacheStats struct {
+	Hits    uint64 `json:"hits"`
+	Misses  uint64 `json:"misses"`
+	Entries int    `json:"entries"`
+}
+
+func NoCache() Condition {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Set(noCacheKey, true)
+	}
+}
+
+func (ins *Instance) CacheStats() (stats CacheStats) {
+	if c := ins.cache; c != nil {
+		c.mu.Lock()
+		stats.Entries = c.lru.Len()
+		c.mu.Unlock()
+		stats.Hits, stats.Misses = c.hits.Load(), c.misses.Load()
+	}
+	return
+}
+
+func (ins *Instance) FlushCache() {
+	if ins.cache != nil {
+		ins.cache.flush()
+	}
+}
+
+func (ins *Instance) cached(table Data, dest any, query func(db *gorm.DB) *gorm.DB) *gorm.DB {
+	db := ins.DB()
+	c := ins.cache
+	if c == nil || ins.tx != nil {
+		return query(db)
+	}
+	name := table.TableName()
+	ttl := c.ttlOf(name)
+	if ttl <= 0 {
+		return query(db)
+	}
+	dry := query(db.Session(&gorm.Session{DryRun: true, Logger: logger.Discard}))
+	if skip, _ := dry.Get(noCacheKey); dry.Error != nil || skip == true || len(dry.Statement.Preloads) > 0 {
+		return query(db)
+	}
+	key := fmt.Sprintf("%s\x00%T\x00%s", name, dest, db.Dialector.Explain(dry.Statement.SQL.String(), dry.Statement.Vars...))
+	if rows, ok := c.load(key, dest); ok {
+		res := db.Session(&gorm.Session{})
+		res.RowsAffected = rows
+		res.Statement.Dest = dest
+		return res
+	}
+	stamp := c.stamp(name)
+	res := query(ForcePrimary()(db))
+	if res.Error == nil && res.RowsAffected <= int64(c.maxRows) {
+		c.store(key, name, dest, res.RowsAffected, ttl, stamp)
+	}
+	return res
+}
+
+type queryCache struct {
+	ttl        time.Duration
+	tables     map[string]time.Duration
+	maxEntries int
+	maxRows    int
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+	byTable map[string]map[string]struct{}
+	pending map[gorm.ConnPool]map[string]struct{}
+	writes  map[string]uint64
+	flushes uint64
+	hits    atomic.Uint64
+	misses  atomic.Uint64
+}
+
+type cacheEntry struct {
+	key     string
+	table   string
+	value   reflect.Value
+	rows    int64
+	expires time.Time
+}
+
+func newQueryCache(cfg *CacheConfig) *queryCache {
+	c := &queryCache{
+		ttl:        duration(cfg.TTL),
+		tables:     make(map[string]time.Duration, len(cfg.Tables)),
+		maxEntries: cfg.MaxEntries,
+		maxRows:    cfg.MaxRows,
+		lru:        list.New(),
+		entries:    make(map[string]*list.Element),
+		byTable:    make(map[string]map[string]struct{}),
+		pending:    make(map[gorm.ConnPool]map[string]struct{}),
+		writes:     make(map[string]uint64),
+	}
+	for table, ttl := range cfg.Tables {
+		c.tables[table] = duration(ttl)
+	}
+	if c.maxEntries <= 0 {
+		c.maxEntries = defaultCacheMaxEntries
+	}
+	if c.maxRows <= 0 {
+		c.maxRows = defaultCacheMaxRows
+	}
+	return c
+}
+
+func (c *queryCache) register(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().After("gorm:create").Register(cacheCallbackName, c.invalidate),
+		cb.Update().After("gorm:update").Register(cacheCallbackName, c.invalidate),
+		cb.Delete().After("gorm:delete").Register(cacheCallbackName, c.invalidate),
+		cb.Create().After("gorm:commit_or_rollback_transaction").Register(cacheCommitCallbackName, c.settle),
+		cb.Update().After("gorm:commit_or_rollback_transaction").Register(cacheCommitCallbackName, c.settle),
+		cb.Delete().After("gorm:commit_or_rollback_transaction").Register(cacheCommitCallbackName, c.settle),
+		cb.Raw().After("gorm:raw").Register(cacheCallbackName, func(db *gorm.DB) { c.written(db, "") }),
+	)
+}
+
+func (c *queryCache) ttlOf(table string) time.Duration {
+	if ttl, ok := c.tables[table]; ok {
+		return ttl
+	}
+	return c.ttl
+}
+
+func (c *queryCache) load(key string, dest any) (rows int64, ok bool) {
+	c.mu.Lock()
+	elem, found := c.entries[key]
+	if found {
+		if entry := elem.Value.(*cacheEntry); time.Now().Before(entry.expires) {
+			c.lru.MoveToFront(elem)
+			c.mu.Unlock()
+			reflect.ValueOf(dest).Elem().Set(deepCopy(entry.value, nil))
+			c.hits.Add(1)
+			return entry.rows, true
+		}
+		c.remove(elem)
+	}
+	c.mu.Unlock()
+	c.misses.Add(1)
+	return
+}
+
+func (c *queryCache) stamp(table string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.flushes + c.writes[table]
+}
+
+func (c *queryCache) store(key, table string, dest any, rows int64, ttl time.Duration, stamp uint64) {
+	entry := &cacheEntry{
+		key:     key,
+		table:   table,
+		value:   deepCopy(reflect.ValueOf(dest).Elem(), nil),
+		rows:    rows,
+		expires: time.Now().Add(ttl),
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.flushes+c.writes[table] != stamp {
+		return
+	}
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	if c.byTable[table] == nil {
+		c.byTable[table] = make(map[string]struct{})
+	}
+	c.byTable[table][key] = struct{}{}
+	for c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *queryCache) remove(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*cacheEntry)
+	delete(c.entries, entry.key)
+	if keys := c.byTable[entry.table]; keys != nil {
+		if delete(keys, entry.key); len(keys) == 0 {
+			delete(c.byTable, entry.table)
+		}
+	}
+}
+
+func (c *queryCache) invalidate(db *gorm.DB) {
+	c.written(db, db.Statement.Table)
+}
+
+func (c *queryCache) written(db *gorm.DB, table string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if pool := db.Statement.ConnPool; isTx(pool) && !startedTx(db) {
+		if len(c.pending) >= c.maxEntries {
+			c.pending = make(map[gorm.ConnPool]map[string]struct{})
+		}
+		if c.pending[pool] == nil {
+			c.pending[pool] = make(map[string]struct{})
+		}
+		c.pending[pool][table] = struct{}{}
+	}
+	c.drop(table)
+}
+
+func (c *queryCache) settle(db *gorm.DB) {
+	if startedTx(db) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.drop(db.Statement.Table)
+	}
+}
+
+func (c *queryCache) commit(pool gorm.ConnPool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for table := range c.pending[pool] {
+		c.drop(table)
+	}
+	delete(c.pending, pool)
+}
+
+func (c *queryCache) flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.drop("")
+}
+
+func (c *queryCache) drop(table string) {
+	if table == "" {
+		c.flushes++
+		c.lru.Init()
+		c.entries = make(map[string]*list.Element)
+		c.byTable = make(map[string]map[string]struct{})
+		return
+	}
+	c.writes[table]++
+	for key := range c.byTable[table] {
+		c.remove(c.entries[key])
+	}
+}
+
+func startedTx(db *gorm.DB) (ok bool) {
+	_, ok = db.InstanceGet("gorm:started_transaction")
+	return
+}
+
+func isTx(pool gorm.ConnPool) (ok bool) {
+	_, ok = pool.(gorm.TxCommitter)
+	return
+}
+
+func deepCopy(v reflect.Value, seen map[uintptr]reflect.Value) reflect.Value {
+	if !v.IsValid() {
+		return v
+	}
+	cp := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return cp
+		}
+		if seen == nil {
+			seen = make(map[uintptr]reflect.Value)
+		}
+		if p, ok := seen[v.Pointer()]; ok {
+			return p
+		}
+		p := reflect.New(v.Type().Elem())
+		seen[v.Pointer()] = p
+		p.Elem().Set(deepCopy(v.Elem(), seen))
+		return p
+	case reflect.Struct:
+		cp.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if field := cp.Field(i); field.CanSet() {
+				field.Set(deepCopy(v.Field(i), seen))
+			}
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return cp
+		}
+		cp.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Len()))
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			reflect.Copy(cp, v)
+			return cp
+		}
+		for i := 0; i < v.Len(); i++ {
+			cp.Index(i).Set(deepCopy(v.Index(i), seen))
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			cp.Index(i).Set(deepCopy(v.Index(i), seen))
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return cp
+		}
+		cp.Set(reflect.MakeMapWithSize(v.Type(), v.Len()))
+		iter := v.MapRange()
+		for iter.Next() {
+			cp.SetMapIndex(iter.Key(), deepCopy(iter.Value(), seen))
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			cp.Set(deepCopy(v.Elem(), seen))
+		}
+	default:
+		cp.Set(v)
+	}
+	return cp
+}
+
+const (
+	noCacheKey              = "rdb:no_cache"
+	cacheCallbackName       = "rdb:cache"
+	cacheCommitCallbackName = "rdb:cache_commit"
+	defaultCacheMaxEntries  = 1000
+	defaultCacheMaxRows     = 1000
+)
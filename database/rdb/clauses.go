@@ -2,6 +2,7 @@ package rdb
 
 import (
 	"fmt"
+	"strings"
 
 	"gorm.io/gorm/clause"
 )
@@ -21,10 +22,15 @@ func LikeClause(field string, ft FuzzyType, value string) clause.Expression {
 	if ft&fuzzyTypeMsk != FuzzyTypeLeft {
 		right = FuzzySymbol
 	}
-	return clause.Like{
-		Column: field,
-		Value:  fmt.Sprintf("%s%s%s", left, value, right),
-	}
+	return likeEscape(clause.Column{Name: field}, fmt.Sprintf("%s%s%s", left, escapeLike(value), right))
+}
+
+func likeEscape(column clause.Column, pattern string) clause.Expression {
+	return clause.Expr{SQL: "? LIKE ? ESCAPE '" + likeEscapeChar + "'", Vars: []any{column, pattern}}
+}
+
+func escapeLike(value string) string {
+	return likeReplacer.Replace(value)
 }
 
 func GTEClause(field string, value any) clause.Expression {
@@ -39,12 +45,21 @@ func eqClause(field string, value any) clause.Eq {
 	return clause.Eq{Column: field, Value: value}
 }
 
-func JoinClause(field string) clause.Expression {
+func ColumnEqualClause(left, right string) clause.Expression {
+	return clause.Eq{
+		Column: clause.Column{Name: left},
+		Value:  clause.Column{Name: right},
+	}
+}
+
+func JoinClause(joinType JoinType, table, alias string, on ...clause.Expression) clause.Expression {
 	return clause.Join{
-		Type:       clause.LeftJoin,
-		Table:      clause.Table{},
-		ON:         clause.Where{},
-		Using:      nil,
-		Expression: nil,
+		Type:  joinType,
+		Table: clause.Table{Name: table, Alias: alias},
+		ON:    clause.Where{Exprs: on},
 	}
 }
+
+var likeReplacer = strings.NewReplacer(likeEscapeChar, likeEscapeChar+likeEscapeChar, "%", likeEscapeChar+"%", "_", likeEscapeChar+"_")
+
+const likeEscapeChar = "!"
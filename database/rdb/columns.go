@@ -0,0 +1,86 @@
+package rdb
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	allowLists    = make(map[string]map[string]struct{})
+	allowListLock sync.RWMutex
+	identifier    = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+)
+
+func AllowColumns(table Data, columns ...string) {
+	allowListLock.Lock()
+	defer allowListLock.Unlock()
+	allowed, ok := allowLists[table.TableName()]
+	if !ok {
+		allowed = make(map[string]struct{}, len(columns))
+		allowLists[table.TableName()] = allowed
+	}
+	for _, column := range columns {
+		allowed[column] = struct{}{}
+	}
+}
+
+func ValidColumn(name string) bool {
+	return identifier.MatchString(name)
+}
+
+func column(db *gorm.DB, name string) (col clause.Column, ok bool) {
+	if err := checkColumn(db, name); err != nil {
+		_ = db.AddError(err)
+		return
+	}
+	col, ok = clause.Column{Name: name}, true
+	if _, qualify := db.Get(qualifyColumnsKey); qualify && !strings.Contains(name, ".") {
+		col.Table = clause.CurrentTable
+	}
+	return
+}
+
+func checkColumn(db *gorm.DB, name string) error {
+	if !ValidColumn(name) {
+		return fmt.Errorf("invalid column %q", name)
+	}
+	allowListLock.RLock()
+	defer allowListLock.RUnlock()
+	if len(allowLists) == 0 {
+		return nil
+	}
+	table := tableOf(db)
+	if idx := strings.IndexByte(name, '.'); idx >= 0 {
+		if _, ok := allowLists[name[:idx]]; ok {
+			table = name[:idx]
+		}
+		name = name[idx+1:]
+	}
+	if allowed, ok := allowLists[table]; ok {
+		if _, ok = allowed[name]; !ok {
+			return fmt.Errorf("column %s not allowed for table %s", name, table)
+		}
+	}
+	return nil
+}
+
+func tableOf(db *gorm.DB) (table string) {
+	if table = db.Statement.Table; table == "" && db.Statement.Model != nil {
+		model := db.Statement.Model
+		if rv := reflect.ValueOf(model); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			model = reflect.New(rv.Type().Elem()).Interface()
+		}
+		if t, ok := model.(Data); ok {
+			table = t.TableName()
+		}
+	}
+	return
+}
+
+const qualifyColumnsKey = "rdb:qualify_columns"
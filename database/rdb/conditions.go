@@ -2,11 +2,13 @@ package rdb
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 
 	"github.com/basebytes/types"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
 )
 
 func Select(fields ...string) Condition {
@@ -21,7 +23,9 @@ func Select(fields ...string) Condition {
 func Equal(field string, value any) Condition {
 	return func(db *gorm.DB) *gorm.DB {
 		if field != "" {
-			db = db.Where(fmt.Sprintf("%s = ?", field), value)
+			if col, ok := column(db, field); ok {
+				db = db.Where(clause.Eq{Column: col, Value: value})
+			}
 		}
 		return db
 	}
@@ -30,7 +34,9 @@ func Equal(field string, value any) Condition {
 func NotEqual(field string, value any) Condition {
 	return func(db *gorm.DB) *gorm.DB {
 		if field != "" {
-			db = db.Where(fmt.Sprintf("%s != ?", field), value)
+			if col, ok := column(db, field); ok {
+				db = db.Where(clause.Neq{Column: col, Value: value})
+			}
 		}
 		return db
 	}
@@ -39,7 +45,9 @@ func NotEqual(field string, value any) Condition {
 func In(field string, values ...any) Condition {
 	return func(db *gorm.DB) *gorm.DB {
 		if field != "" {
-			db = db.Where(fmt.Sprintf("%s in ?", field), values)
+			if col, ok := column(db, field); ok {
+				db = db.Where(clause.IN{Column: col, Values: flatten(values)})
+			}
 		}
 		return db
 	}
@@ -47,19 +55,45 @@ func In(field string, values ...any) Condition {
 
 func Or(conditions ...Condition) Condition {
 	return func(db *gorm.DB) *gorm.DB {
-		db = db.Or(newDryRun().Scopes(conditions...))
+		if expr := group(db, conditions...); expr != nil {
+			db = db.Or(expr)
+		}
 		return db
 	}
 }
 
-func And(conditions ...Condition) Condition {
+func AnyOf(conditions ...Condition) Condition {
 	return func(db *gorm.DB) *gorm.DB {
-		if len(conditions) > 0 {
-			var db1 *gorm.DB
-			for _, cond := range conditions {
-				db1 = cond(db)
+		exprs := make([]clause.Expression, 0, len(conditions))
+		for _, cond := range conditions {
+			if expr := group(db, cond); expr != nil {
+				exprs = append(exprs, expr)
 			}
-			db = db.Where(db1)
+		}
+		switch len(exprs) {
+		case 0:
+		case 1:
+			db = db.Where(exprs[0])
+		default:
+			db = db.Where(clause.Or(exprs...))
+		}
+		return db
+	}
+}
+
+func And(conditions ...Condition) Condition {
+	return func(db *gorm.DB) *gorm.DB {
+		if expr := group(db, conditions...); expr != nil {
+			db = db.Where(expr)
+		}
+		return db
+	}
+}
+
+func Not(conditions ...Condition) Condition {
+	return func(db *gorm.DB) *gorm.DB {
+		if expr := group(db, conditions...); expr != nil {
+			db = db.Where(clause.Not(expr))
 		}
 		return db
 	}
@@ -68,7 +102,9 @@ func And(conditions ...Condition) Condition {
 func NotIn(field string, values ...any) Condition {
 	return func(db *gorm.DB) *gorm.DB {
 		if field != "" {
-			db = db.Where(fmt.Sprintf("%s not in ?", field), values)
+			if col, ok := column(db, field); ok {
+				db = db.Where(clause.Not(clause.IN{Column: col, Values: flatten(values)}))
+			}
 		}
 		return db
 	}
@@ -79,9 +115,17 @@ func Range(field string, op OpType, value any) Condition {
 		if field == "" || value == nil {
 			return db
 		}
-		switch op {
-		case LT, GT, LTE, GTE:
-			db = db.Where(fmt.Sprintf("%s %s?", field, op), value)
+		if col, ok := column(db, field); ok {
+			switch op {
+			case LT:
+				db = db.Where(clause.Lt{Column: col, Value: value})
+			case GT:
+				db = db.Where(clause.Gt{Column: col, Value: value})
+			case LTE:
+				db = db.Where(clause.Lte{Column: col, Value: value})
+			case GTE:
+				db = db.Where(clause.Gte{Column: col, Value: value})
+			}
 		}
 		return db
 	}
@@ -97,7 +141,9 @@ func Like(field string, ft FuzzyType, value string) Condition {
 	}
 	return func(db *gorm.DB) *gorm.DB {
 		if field != "" && value != "" {
-			db = db.Where(fmt.Sprintf("%s like ?", field), fmt.Sprintf("%s%s%s", left, value, right))
+			if col, ok := column(db, field); ok {
+				db = db.Where(likeEscape(col, fmt.Sprintf("%s%s%s", left, escapeLike(value), right)))
+			}
 		}
 		return db
 	}
@@ -113,19 +159,87 @@ func Join(name string, clauses ...clause.Expression) Condition {
 	}
 }
 
-func Association(name string) Condition {
+func InnerJoin(table, alias string, on ...clause.Expression) Condition {
+	return joinTable(InnerJoinType, table, alias, on...)
+}
+
+func LeftJoin(table, alias string, on ...clause.Expression) Condition {
+	return joinTable(LeftJoinType, table, alias, on...)
+}
+
+func RightJoin(table, alias string, on ...clause.Expression) Condition {
+	return joinTable(RightJoinType, table, alias, on...)
+}
+
+func joinTable(joinType JoinType, table, alias string, on ...clause.Expression) Condition {
 	return func(db *gorm.DB) *gorm.DB {
-		if name != "" {
-			db.Association(name)
+		if table == "" {
+			return db
 		}
-		return db
+		if !ValidColumn(table) || (alias != "" && !ValidColumn(alias)) {
+			_ = db.AddError(fmt.Errorf("invalid join table %q alias %q", table, alias))
+			return db
+		}
+		return db.Joins("?", JoinClause(joinType, table, alias, on...))
 	}
 }
 
+func Association(name string, conditions ...Condition) Condition {
+	return func(db *gorm.DB) *gorm.DB {
+		if name == "" {
+			return db
+		}
+		if len(conditions) == 0 {
+			return db.Joins(name)
+		}
+		related, err := relationSchema(db, name)
+		if err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		sub := db.Session(&gorm.Session{NewDB: true}).Model(reflect.New(related.ModelType).Interface()).Set(qualifyColumnsKey, true)
+		for _, cond := range conditions {
+			sub = cond(sub)
+		}
+		if sub.Error != nil {
+			_ = db.AddError(sub.Error)
+			return db
+		}
+		return db.Joins(name, sub)
+	}
+}
+
+func relationSchema(db *gorm.DB, name string) (related *schema.Schema, err error) {
+	if db.Statement.Model == nil {
+		return nil, fmt.Errorf("association %s needs a model", name)
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err = stmt.Parse(db.Statement.Model); err != nil {
+		return
+	}
+	related = stmt.Schema
+	for _, part := range strings.Split(name, ".") {
+		rel, ok := related.Relationships.Relations[part]
+		if !ok {
+			return nil, fmt.Errorf("unknown association %s of table %s", name, stmt.Schema.Table)
+		}
+		related = rel.FieldSchema
+	}
+	return
+}
+
 func Group(fields string) Condition {
 	return func(db *gorm.DB) *gorm.DB {
 		if fields != "" {
-			return db.Group(fields)
+			columns := make([]clause.Column, 0, strings.Count(fields, ",")+1)
+			for _, field := range strings.Split(fields, ",") {
+				col, ok := column(db, strings.TrimSpace(field))
+				if !ok {
+					return db
+				}
+				columns = append(columns, col)
+			}
+			db = db.Clauses(clause.GroupBy{Columns: columns})
 		}
 		return db
 	}
@@ -137,7 +251,10 @@ func OrderBy(field string, order ...OrderType) Condition {
 		oderBy = ASC
 	}
 	return func(db *gorm.DB) *gorm.DB {
-		return db.Order(fmt.Sprintf("%s %s", field, oderBy))
+		if col, ok := column(db, field); ok {
+			db = db.Order(clause.OrderByColumn{Column: col, Desc: oderBy == DESC})
+		}
+		return db
 	}
 }
 
@@ -155,24 +272,6 @@ func Preload(name string, conditions ...Condition) Condition {
 	}
 }
 
-func JsonContains(field string, value any) Condition {
-	return func(db *gorm.DB) *gorm.DB {
-		if field != "" && value != nil {
-			db = db.Where(fmt.Sprintf("JSON_CONTAINS(%s,JSON_ARRAY(?))", field), value)
-		}
-		return db
-	}
-}
-
-func JsonSearch(field string, value any) Condition {
-	return func(db *gorm.DB) *gorm.DB {
-		if field != "" && value != nil {
-			db = db.Where(fmt.Sprintf("JSON_SEARCH(%s,'all',?)", field), value)
-		}
-		return db
-	}
-}
-
 func Page(offset, limit int) Condition {
 	if offset < 0 {
 		offset = 0
@@ -190,12 +289,58 @@ func TimeScope(field string, start, end *types.Time) Condition {
 		if start == nil && end == nil {
 			return db
 		}
+		col, ok := column(db, field)
+		if !ok {
+			return db
+		}
 		if start != nil {
-			db = db.Where(fmt.Sprintf("%s >=?", field), start.String())
+			db = db.Where(clause.Gte{Column: col, Value: start.String()})
 		}
 		if end != nil {
-			db = db.Where(fmt.Sprintf("%s <=?", field), end.String())
+			db = db.Where(clause.Lte{Column: col, Value: end.String()})
 		}
 		return db
 	}
 }
+
+func flatten(values []any) []any {
+	if len(values) == 1 {
+		if rv := reflect.ValueOf(values[0]); rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8 {
+			flat := make([]any, 0, rv.Len())
+			for i := 0; i < rv.Len(); i++ {
+				flat = append(flat, rv.Index(i).Interface())
+			}
+			return flat
+		}
+	}
+	return values
+}
+
+func group(db *gorm.DB, conditions ...Condition) clause.Expression {
+	if len(conditions) == 0 || db.Error != nil {
+		return nil
+	}
+	sub := subQuery(db, conditions...)
+	if sub.Error != nil {
+		_ = db.AddError(sub.Error)
+		return nil
+	}
+	if where, ok := sub.Statement.Clauses["WHERE"].Expression.(clause.Where); ok {
+		return clause.And(where.Exprs...)
+	}
+	return nil
+}
+
+func subQuery(db *gorm.DB, conditions ...Condition) *gorm.DB {
+	sub := db.Session(&gorm.Session{NewDB: true}).Model(db.Statement.Model)
+	if db.Statement.Table != "" {
+		sub = sub.Table(db.Statement.Table)
+	}
+	if qualify, ok := db.Get(qualifyColumnsKey); ok {
+		sub = sub.Set(qualifyColumnsKey, qualify)
+	}
+	for _, cond := range conditions {
+		sub = cond(sub)
+	}
+	return sub
+}
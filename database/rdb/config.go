@@ -2,10 +2,15 @@ package rdb
 
 import (
 	"fmt"
-	"os"
+	"math"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/glebarez/sqlite"
-	"gorm.io/driver/mysql"
+	"github.com/basebytes/types"
 	"gorm.io/gorm"
 )
 
@@ -18,68 +23,268 @@ type Config struct {
 	Port        int    `json:"port,omitempty"`
 	MaxOpenCons int    `json:"maxOpenCons,omitempty"`
 	MaxIdleCons int    `json:"maxIdleCons,omitempty"`
+	SSLMode     string `json:"sslMode,omitempty"`
+	Schema      string `json:"schema,omitempty"`
+	TimeZone    string `json:"timeZone,omitempty"`
+
+	ConnMaxLifetime *types.Duration   `json:"connMaxLifetime,omitempty"`
+	ConnMaxIdleTime *types.Duration   `json:"connMaxIdleTime,omitempty"`
+	DialTimeout     *types.Duration   `json:"dialTimeout,omitempty"`
+	ReadTimeout     *types.Duration   `json:"readTimeout,omitempty"`
+	WriteTimeout    *types.Duration   `json:"writeTimeout,omitempty"`
+	Params          map[string]string `json:"params,omitempty"`
+	HealthCheck     *types.Duration   `json:"healthCheck,omitempty"`
+	Logger          *LoggerConfig     `json:"logger,omitempty"`
+	Cache           *CacheConfig      `json:"cache,omitempty"`
+
+	Replicas      []*Config `json:"replicas,omitempty"`
+	ReplicaPolicy string    `json:"replicaPolicy,omitempty"`
 }
 
 func (c *Config) Init() (err error) {
-	switch c.Driver {
-	case "", driverMysql:
-		err = c.initMysql()
-	case driverSqlite:
-		err = c.initSqlite()
-	default:
+	if factory, ok := getDriver(c.driver()); ok {
+		factory.Default(c)
+		err = factory.Validate(c)
+	} else {
 		err = fmt.Errorf("unSupport database driver %s", c.Driver)
 	}
+	if err == nil && c.Logger != nil {
+		err = c.Logger.Init()
+	}
+	if err == nil && c.Cache != nil {
+		err = c.Cache.Init()
+	}
+	if err == nil && len(c.Replicas) > 0 {
+		err = c.initReplicas()
+	}
 	return
 }
 
-func (c *Config) initSqlite() (err error) {
-	if _, err = os.Stat(c.DataBase); err == nil {
-		err = fmt.Errorf("sqlite db file %s not found", c.DataBase)
-	} else {
-		if c.MaxOpenCons <= 0 {
-			c.MaxOpenCons = defaultSqliteMaxOpenCons
+func (c *Config) initReplicas() (err error) {
+	switch c.ReplicaPolicy {
+	case "":
+		c.ReplicaPolicy = ReplicaPolicyRoundRobin
+	case ReplicaPolicyRoundRobin, ReplicaPolicyRandom:
+	default:
+		return fmt.Errorf("unSupport replica policy %s", c.ReplicaPolicy)
+	}
+	for i, replica := range c.Replicas {
+		if replica == nil {
+			err = fmt.Errorf("replica[%d] config not found", i)
+		} else if len(replica.Replicas) > 0 {
+			err = fmt.Errorf("replica[%d] can not have replicas", i)
+		} else {
+			replica.inherit(c)
+			if err = replica.Init(); err != nil {
+				err = fmt.Errorf("init replica[%d] failed:%s", i, err)
+			}
 		}
-		if c.MaxIdleCons <= 0 {
-			c.MaxIdleCons = defaultSqliteMaxIdleCons
+		if err != nil {
+			break
 		}
 	}
 	return
 }
 
-func (c *Config) Dial() (dial gorm.Dialector) {
-	switch c.Driver {
-	case "", driverMysql:
-		dial = mysql.Open(fmt.Sprintf(mysqlDataSourceNameFormat, c.UserName, c.Password, c.Host, c.Port, c.DataBase))
-	case driverSqlite:
-		dial = sqlite.Open(fmt.Sprintf(sqliteDataSourceNameFormat, c.DataBase))
+func (c *Config) inherit(primary *Config) {
+	if c.Driver == "" {
+		c.Driver = primary.Driver
+	}
+	if c.Host == "" {
+		c.Host = primary.Host
+	}
+	if c.DataBase == "" {
+		c.DataBase = primary.DataBase
+	}
+	if c.UserName == "" {
+		c.UserName = primary.UserName
+	}
+	if c.Password == "" {
+		c.Password = primary.Password
+	}
+	if c.Port <= 0 {
+		c.Port = primary.Port
 	}
-	return
-}
-
-func (c *Config) initMysql() (err error) {
 	if c.MaxOpenCons <= 0 {
-		c.MaxOpenCons = defaultMysqlMaxOpenCons
+		c.MaxOpenCons = primary.MaxOpenCons
 	}
 	if c.MaxIdleCons <= 0 {
-		c.MaxIdleCons = defaultMysqlMaxIdleCons
+		c.MaxIdleCons = primary.MaxIdleCons
 	}
-	if c.Port <= 0 {
-		c.Port = defaultMysqlPort
+	if c.SSLMode == "" {
+		c.SSLMode = primary.SSLMode
+	}
+	if c.Schema == "" {
+		c.Schema = primary.Schema
+	}
+	if c.TimeZone == "" {
+		c.TimeZone = primary.TimeZone
+	}
+	if c.ConnMaxLifetime == nil {
+		c.ConnMaxLifetime = primary.ConnMaxLifetime
+	}
+	if c.ConnMaxIdleTime == nil {
+		c.ConnMaxIdleTime = primary.ConnMaxIdleTime
+	}
+	if c.DialTimeout == nil {
+		c.DialTimeout = primary.DialTimeout
+	}
+	if c.ReadTimeout == nil {
+		c.ReadTimeout = primary.ReadTimeout
+	}
+	if c.WriteTimeout == nil {
+		c.WriteTimeout = primary.WriteTimeout
+	}
+	if len(c.Params) == 0 {
+		c.Params = primary.Params
+	}
+}
+
+func (c *Config) clone() *Config {
+	cfg := *c
+	if c.Params != nil {
+		cfg.Params = make(map[string]string, len(c.Params))
+		for key, value := range c.Params {
+			cfg.Params[key] = value
+		}
+	}
+	if c.Replicas != nil {
+		cfg.Replicas = make([]*Config, 0, len(c.Replicas))
+		for _, replica := range c.Replicas {
+			if replica != nil {
+				replica = replica.clone()
+			}
+			cfg.Replicas = append(cfg.Replicas, replica)
+		}
+	}
+	cfg.ConnMaxLifetime = cloneDuration(c.ConnMaxLifetime)
+	cfg.ConnMaxIdleTime = cloneDuration(c.ConnMaxIdleTime)
+	cfg.DialTimeout = cloneDuration(c.DialTimeout)
+	cfg.ReadTimeout = cloneDuration(c.ReadTimeout)
+	cfg.WriteTimeout = cloneDuration(c.WriteTimeout)
+	cfg.HealthCheck = cloneDuration(c.HealthCheck)
+	cfg.Logger = c.Logger.clone()
+	cfg.Cache = c.Cache.clone()
+	return &cfg
+}
+
+func (c *Config) equal(other *Config) bool {
+	return reflect.DeepEqual(c, other)
+}
+
+func (c *Config) params(defaults map[string]string) url.Values {
+	values := make(url.Values, len(defaults)+len(c.Params))
+	for key, value := range defaults {
+		values.Set(key, value)
+	}
+	for key, value := range c.Params {
+		values.Set(key, value)
+	}
+	return values
+}
+
+func (c *Config) mysqlDSN() string {
+	defaults := map[string]string{"parseTime": "true", "charset": "utf8mb4", "loc": "Local"}
+	if d := duration(c.DialTimeout); d > 0 {
+		defaults["timeout"] = d.String()
+	}
+	if d := duration(c.ReadTimeout); d > 0 {
+		defaults["readTimeout"] = d.String()
+	}
+	if d := duration(c.WriteTimeout); d > 0 {
+		defaults["writeTimeout"] = d.String()
+	}
+	return fmt.Sprintf(mysqlDataSourceNameFormat, c.UserName, c.Password, c.Host, c.Port, c.DataBase, c.params(defaults).Encode())
+}
+
+func (c *Config) sqliteDSN() string {
+	defaults := map[string]string{"cache": "shared", "mode": "rwc", "_journal_mode": "WAL"}
+	return fmt.Sprintf(sqliteDataSourceNameFormat, c.DataBase, c.params(defaults).Encode())
+}
+
+func (c *Config) postgresDSN() string {
+	defaults := map[string]string{
+		"host":        c.Host,
+		"port":        strconv.Itoa(c.Port),
+		"user":        c.UserName,
+		"password":    c.Password,
+		"dbname":      c.DataBase,
+		"sslmode":     c.SSLMode,
+		"search_path": c.Schema,
+		"TimeZone":    c.TimeZone,
+	}
+	if d := duration(c.DialTimeout); d > 0 {
+		defaults["connect_timeout"] = strconv.Itoa(int(math.Ceil(d.Seconds())))
+	}
+	values := c.params(defaults)
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if values.Get(key) == "" {
+			continue
+		}
+		pairs = append(pairs, fmt.Sprintf("%s='%s'", key, postgresValueReplacer.Replace(values.Get(key))))
+	}
+	return strings.Join(pairs, " ")
+}
+
+func cloneDuration(d *types.Duration) *types.Duration {
+	if d == nil {
+		return nil
+	}
+	return &types.Duration{Duration: d.Duration}
+}
+
+func duration(d *types.Duration) (v time.Duration) {
+	if d != nil {
+		v = d.Duration
+	}
+	return
+}
+
+var postgresValueReplacer = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+func (c *Config) Dial() (dial gorm.Dialector) {
+	if factory, ok := getDriver(c.driver()); ok {
+		dial = factory.Dialector(c)
 	}
 	return
 }
 
+func (c *Config) driver() string {
+	if c.Driver == "" {
+		return driverMysql
+	}
+	return c.Driver
+}
+
 const (
-	mysqlDataSourceNameFormat  = "%s:%s@tcp(%s:%d)/%s?parseTime=true&charset=utf8mb4&loc=Local"
-	sqliteDataSourceNameFormat = "file:%s?cache=shared&mode=rwc&_journal_mode=WAL"
+	mysqlDataSourceNameFormat  = "%s:%s@tcp(%s:%d)/%s?%s"
+	sqliteDataSourceNameFormat = "file:%s?%s"
 	defaultMysqlPort           = 3306
 	defaultMysqlMaxOpenCons    = 6
 	defaultMysqlMaxIdleCons    = 6
 	defaultSqliteMaxOpenCons   = 1
 	defaultSqliteMaxIdleCons   = 1
+	defaultPostgresPort        = 5432
+	defaultPostgresMaxOpenCons = 6
+	defaultPostgresMaxIdleCons = 6
+	defaultPostgresSSLMode     = "disable"
+	defaultPostgresSchema      = "public"
+	defaultPostgresTimeZone    = "UTC"
+)
+
+const (
+	ReplicaPolicyRoundRobin = "roundRobin"
+	ReplicaPolicyRandom     = "random"
 )
 
 const (
-	driverMysql  = "mysql"
-	driverSqlite = "sqlite"
+	driverMysql    = "mysql"
+	driverSqlite   = "sqlite"
+	driverPostgres = "postgres"
 )
@@ -0,0 +1,191 @@
+package rdb
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+type SortKey struct {
+	Field string    `json:"field"`
+	Order OrderType `json:"order,omitempty"`
+}
+
+type Keyset struct {
+	Keys      []SortKey `json:"keys"`
+	Limit     int       `json:"limit"`
+	Cursor    string    `json:"cursor,omitempty"`
+	SkipCount bool      `json:"skipCount,omitempty"`
+}
+
+func KeysetPage(keyset *Keyset) Condition {
+	return keyset.condition(0)
+}
+
+func (ins *Instance) CursorQuery(table Data, result any, keyset *Keyset, conditions ...Condition) (next string, total int64, err error) {
+	db := ins.DB().Model(table).Where(table).Scopes(conditions...)
+	if !keyset.SkipCount {
+		if err = db.Count(&total).Error; err != nil {
+			return
+		}
+	}
+	if err = db.Scopes(keyset.condition(1)).Find(result).Error; err == nil {
+		next, err = keyset.next(db, table, result)
+	}
+	return
+}
+
+func (k *Keyset) condition(extra int) Condition {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(k.Keys) == 0 {
+			_ = db.AddError(emptyKeysetErr)
+			return db
+		}
+		columns := make([]clause.Column, 0, len(k.Keys))
+		for _, key := range k.Keys {
+			col, ok := column(db, key.Field)
+			if !ok {
+				return db
+			}
+			columns = append(columns, col)
+		}
+		if k.Cursor != "" {
+			values, err := decodeCursor(k.Cursor)
+			if err == nil && len(values) != len(k.Keys) {
+				err = invalidCursorErr
+			}
+			if err != nil {
+				_ = db.AddError(err)
+				return db
+			}
+			db = db.Where(k.after(values))
+		}
+		for i, col := range columns {
+			db = db.Order(clause.OrderByColumn{Column: col, Desc: k.Keys[i].desc()})
+		}
+		if k.Limit > 0 {
+			db = db.Limit(k.Limit + extra)
+		}
+		return db
+	}
+}
+
+func (k *Keyset) after(values []any) clause.Expression {
+	exprs := make([]clause.Expression, 0, len(k.Keys))
+	for i, key := range k.Keys {
+		conds := make([]clause.Expression, 0, i+1)
+		for j := 0; j < i; j++ {
+			conds = append(conds, clause.Eq{Column: clause.Column{Name: k.Keys[j].Field}, Value: values[j]})
+		}
+		if key.desc() {
+			conds = append(conds, clause.Lt{Column: clause.Column{Name: key.Field}, Value: values[i]})
+		} else {
+			conds = append(conds, clause.Gt{Column: clause.Column{Name: key.Field}, Value: values[i]})
+		}
+		exprs = append(exprs, clause.And(conds...))
+	}
+	return clause.Or(exprs...)
+}
+
+func (s SortKey) desc() bool {
+	return strings.ToUpper(s.Order) != ASC
+}
+
+func (k *Keyset) next(db *gorm.DB, table Data, result any) (next string, err error) {
+	rv := reflect.ValueOf(result)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return "", fmt.Errorf("cursor result must be a slice pointer, got %T", result)
+	}
+	items := rv.Elem()
+	if k.Limit <= 0 || items.Len() <= k.Limit {
+		return
+	}
+	items.Set(items.Slice(0, k.Limit))
+	stmt := &gorm.Statement{DB: db}
+	if err = stmt.Parse(table); err != nil {
+		return
+	}
+	last := reflect.Indirect(items.Index(k.Limit - 1))
+	values := make([]any, 0, len(k.Keys))
+	for _, key := range k.Keys {
+		name := key.Field
+		if idx := strings.LastIndexByte(name, '.'); idx >= 0 {
+			name = name[idx+1:]
+		}
+		var field *schema.Field
+		if field = stmt.Schema.LookUpField(name); field == nil {
+			return "", fmt.Errorf("cursor key %s not found in %s", key.Field, stmt.Schema.Table)
+		}
+		value, _ := field.ValueOf(db.Statement.Context, last)
+		values = append(values, value)
+	}
+	return encodeCursor(values)
+}
+
+func encodeCursor(values []any) (cursor string, err error) {
+	encoded := make([]any, 0, len(values))
+	for _, value := range values {
+		if valuer, ok := value.(driver.Valuer); ok {
+			if value, err = valuer.Value(); err != nil {
+				return
+			}
+		}
+		if t, ok := value.(time.Time); ok {
+			value = map[string]string{cursorTimeKey: t.Format(time.RFC3339Nano)}
+		}
+		encoded = append(encoded, value)
+	}
+	var data []byte
+	if data, err = json.Marshal(encoded); err == nil {
+		cursor = base64.RawURLEncoding.EncodeToString(data)
+	}
+	return
+}
+
+func decodeCursor(cursor string) (values []any, err error) {
+	var data []byte
+	if data, err = base64.RawURLEncoding.DecodeString(cursor); err != nil {
+		return nil, invalidCursorErr
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var raw []any
+	if err = decoder.Decode(&raw); err != nil {
+		return nil, invalidCursorErr
+	}
+	values = make([]any, 0, len(raw))
+	for _, value := range raw {
+		switch v := value.(type) {
+		case json.Number:
+			if i, e := v.Int64(); e == nil {
+				value = i
+			} else if f, e := v.Float64(); e == nil {
+				value = f
+			}
+		case map[string]any:
+			s, _ := v[cursorTimeKey].(string)
+			if value, err = time.Parse(time.RFC3339Nano, s); err != nil {
+				return nil, invalidCursorErr
+			}
+		}
+		values = append(values, value)
+	}
+	return
+}
+
+var (
+	emptyKeysetErr   = errors.New("keyset sort keys required")
+	invalidCursorErr = errors.New("invalid cursor")
+)
+
+const cursorTimeKey = "t"
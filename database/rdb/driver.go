@@ -0,0 +1,161 @@
+package rdb
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type DriverFactory interface {
+	Default(c *Config)
+	Validate(c *Config) error
+	Dialector(c *Config) gorm.Dialector
+}
+
+var (
+	drivers    = make(map[string]DriverFactory)
+	driverLock sync.RWMutex
+)
+
+func init() {
+	RegisterDriver(driverMysql, mysqlDriver{})
+	RegisterDriver(driverSqlite, sqliteDriver{})
+	RegisterDriver(driverPostgres, postgresDriver{})
+}
+
+func RegisterDriver(name string, factory DriverFactory) {
+	driverLock.Lock()
+	defer driverLock.Unlock()
+	if name == "" {
+		panic("rdb: register driver with empty name")
+	}
+	if factory == nil {
+		panic(fmt.Sprintf("rdb: register driver %s factory is nil", name))
+	}
+	if _, dup := drivers[name]; dup {
+		panic(fmt.Sprintf("rdb: register driver %s twice", name))
+	}
+	drivers[name] = factory
+}
+
+func Drivers() (names []string) {
+	driverLock.RLock()
+	defer driverLock.RUnlock()
+	names = make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+func getDriver(name string) (factory DriverFactory, ok bool) {
+	driverLock.RLock()
+	defer driverLock.RUnlock()
+	factory, ok = drivers[name]
+	return
+}
+
+type mysqlDriver struct{}
+
+func (mysqlDriver) Default(c *Config) {
+	if c.MaxOpenCons <= 0 {
+		c.MaxOpenCons = defaultMysqlMaxOpenCons
+	}
+	if c.MaxIdleCons <= 0 {
+		c.MaxIdleCons = defaultMysqlMaxIdleCons
+	}
+	if c.Port <= 0 {
+		c.Port = defaultMysqlPort
+	}
+}
+
+func (mysqlDriver) Validate(_ *Config) error {
+	return nil
+}
+
+func (mysqlDriver) Dialector(c *Config) gorm.Dialector {
+	return mysql.Open(c.mysqlDSN())
+}
+
+type sqliteDriver struct{}
+
+func (sqliteDriver) Default(c *Config) {
+	if c.MaxOpenCons <= 0 {
+		c.MaxOpenCons = defaultSqliteMaxOpenCons
+	}
+	if c.MaxIdleCons <= 0 {
+		c.MaxIdleCons = defaultSqliteMaxIdleCons
+	}
+}
+
+func (sqliteDriver) Validate(c *Config) (err error) {
+	if _, err = os.Stat(c.DataBase); err != nil {
+		err = fmt.Errorf("sqlite db file %s not found", c.DataBase)
+	}
+	return
+}
+
+func (sqliteDriver) Dialector(c *Config) gorm.Dialector {
+	return sqlite.Open(c.sqliteDSN())
+}
+
+type postgresDriver struct{}
+
+func (postgresDriver) Default(c *Config) {
+	if c.MaxOpenCons <= 0 {
+		c.MaxOpenCons = defaultPostgresMaxOpenCons
+	}
+	if c.MaxIdleCons <= 0 {
+		c.MaxIdleCons = defaultPostgresMaxIdleCons
+	}
+	if c.Port <= 0 {
+		c.Port = defaultPostgresPort
+	}
+	if c.SSLMode == "" {
+		c.SSLMode = defaultPostgresSSLMode
+	}
+	if c.Schema == "" {
+		c.Schema = defaultPostgresSchema
+	}
+	if c.TimeZone == "" {
+		c.TimeZone = localTimeZone()
+	}
+}
+
+func localTimeZone() string {
+	if name := time.Local.String(); name != "Local" && name != "" {
+		return name
+	}
+	if name := strings.TrimPrefix(os.Getenv("TZ"), ":"); name != "" {
+		if _, err := time.LoadLocation(name); err == nil {
+			return name
+		}
+	}
+	if target, err := os.Readlink("/etc/localtime"); err == nil {
+		if idx := strings.Index(target, "zoneinfo/"); idx >= 0 {
+			if name := target[idx+len("zoneinfo/"):]; name != "" {
+				if _, err = time.LoadLocation(name); err == nil {
+					return name
+				}
+			}
+		}
+	}
+	return defaultPostgresTimeZone
+}
+
+func (postgresDriver) Validate(_ *Config) error {
+	return nil
+}
+
+func (postgresDriver) Dialector(c *Config) gorm.Dialector {
+	return postgres.Open(c.postgresDSN())
+}
@@ -0,0 +1,220 @@
+package rdb
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+type FilterOp = string
+
+const (
+	FilterEq      FilterOp = "eq"
+	FilterNe      FilterOp = "ne"
+	FilterGt      FilterOp = "gt"
+	FilterGte     FilterOp = "gte"
+	FilterLt      FilterOp = "lt"
+	FilterLte     FilterOp = "lte"
+	FilterIn      FilterOp = "in"
+	FilterNotIn   FilterOp = "nin"
+	FilterLike    FilterOp = "like"
+	FilterPrefix  FilterOp = "prefix"
+	FilterSuffix  FilterOp = "suffix"
+	FilterBetween FilterOp = "between"
+	FilterNull    FilterOp = "null"
+	FilterNotNull FilterOp = "notNull"
+)
+
+type Filter struct {
+	Field string    `json:"field,omitempty"`
+	Op    FilterOp  `json:"op,omitempty"`
+	Value any       `json:"value,omitempty"`
+	And   []*Filter `json:"and,omitempty"`
+	Or    []*Filter `json:"or,omitempty"`
+}
+
+type Query struct {
+	Filter *Filter    `json:"filter,omitempty"`
+	Sort   []*SortKey `json:"sort,omitempty"`
+	Offset int        `json:"offset,omitempty"`
+	Limit  int        `json:"limit,omitempty"`
+}
+
+type Fields map[string]string
+
+func NewFields(columns ...string) Fields {
+	fields := make(Fields, len(columns))
+	for _, column := range columns {
+		fields[column] = column
+	}
+	return fields
+}
+
+func (f Fields) column(name string) (column string, err error) {
+	var ok bool
+	if column, ok = f[name]; !ok || column == "" {
+		err = fmt.Errorf("field %s not allowed", name)
+	} else if !ValidColumn(column) {
+		err = fmt.Errorf("invalid column %q for field %s", column, name)
+	}
+	return
+}
+
+func (q *Query) Compile(fields Fields, maxLimit int) (conditions []Condition, page Condition, err error) {
+	var where Condition
+	if where, err = q.Filter.Compile(fields); err != nil {
+		return
+	}
+	if where != nil {
+		conditions = append(conditions, where)
+	}
+	for _, sort := range q.Sort {
+		if sort == nil {
+			continue
+		}
+		var column string
+		if column, err = fields.column(sort.Field); err != nil {
+			return
+		}
+		order := strings.ToUpper(sort.Order)
+		if order != "" && order != ASC && order != DESC {
+			err = fmt.Errorf("invalid sort order %s for field %s", sort.Order, sort.Field)
+			return
+		}
+		conditions = append(conditions, OrderBy(column, order))
+	}
+	limit := q.Limit
+	if maxLimit > 0 && (limit <= 0 || limit > maxLimit) {
+		limit = maxLimit
+	}
+	if limit > 0 {
+		page = Page(q.Offset, limit)
+	} else {
+		page = Page(0, -1)
+	}
+	return
+}
+
+func (f *Filter) Compile(fields Fields) (condition Condition, err error) {
+	if f == nil {
+		return
+	}
+	var conditions []Condition
+	if f.Field != "" {
+		if condition, err = f.leaf(fields); err != nil {
+			return
+		}
+		conditions = append(conditions, condition)
+	}
+	if len(f.And) > 0 {
+		var and []Condition
+		if and, err = compileAll(f.And, fields); err != nil {
+			return
+		}
+		if len(and) > 0 {
+			conditions = append(conditions, And(and...))
+		}
+	}
+	if len(f.Or) > 0 {
+		var or []Condition
+		if or, err = compileAll(f.Or, fields); err != nil {
+			return
+		}
+		if len(or) > 0 {
+			conditions = append(conditions, AnyOf(or...))
+		}
+	}
+	switch len(conditions) {
+	case 0:
+		condition = nil
+	case 1:
+		condition = conditions[0]
+	default:
+		condition = And(conditions...)
+	}
+	return
+}
+
+func compileAll(filters []*Filter, fields Fields) (conditions []Condition, err error) {
+	for _, filter := range filters {
+		var condition Condition
+		if condition, err = filter.Compile(fields); err != nil {
+			return
+		}
+		if condition != nil {
+			conditions = append(conditions, condition)
+		}
+	}
+	return
+}
+
+func (f *Filter) leaf(fields Fields) (condition Condition, err error) {
+	var name string
+	if name, err = fields.column(f.Field); err != nil {
+		return
+	}
+	switch f.Op {
+	case "", FilterEq:
+		condition = Equal(name, f.Value)
+	case FilterNe:
+		condition = NotEqual(name, f.Value)
+	case FilterGt:
+		condition = Range(name, GT, f.Value)
+	case FilterGte:
+		condition = Range(name, GTE, f.Value)
+	case FilterLt:
+		condition = Range(name, LT, f.Value)
+	case FilterLte:
+		condition = Range(name, LTE, f.Value)
+	case FilterIn, FilterNotIn:
+		var values []any
+		if values, err = f.values(); err == nil {
+			if f.Op == FilterIn {
+				condition = In(name, values...)
+			} else {
+				condition = NotIn(name, values...)
+			}
+		}
+	case FilterBetween:
+		var values []any
+		if values, err = f.values(); err == nil {
+			if len(values) != 2 {
+				err = fmt.Errorf("filter field %s op %s requires 2 values", f.Field, f.Op)
+			} else {
+				condition = And(Range(name, GTE, values[0]), Range(name, LTE, values[1]))
+			}
+		}
+	case FilterLike, FilterPrefix, FilterSuffix:
+		value, ok := f.Value.(string)
+		if !ok {
+			err = fmt.Errorf("filter field %s op %s requires a string value", f.Field, f.Op)
+			break
+		}
+		ft := FuzzyTypeBoth
+		if f.Op == FilterPrefix {
+			ft = FuzzyTypeRight
+		} else if f.Op == FilterSuffix {
+			ft = FuzzyTypeLeft
+		}
+		condition = Like(name, ft, value)
+	case FilterNull:
+		condition = Equal(name, nil)
+	case FilterNotNull:
+		condition = NotEqual(name, nil)
+	default:
+		err = fmt.Errorf("unSupport filter op %s for field %s", f.Op, f.Field)
+	}
+	return
+}
+
+func (f *Filter) values() (values []any, err error) {
+	rv := reflect.ValueOf(f.Value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("filter field %s op %s requires an array value", f.Field, f.Op)
+	}
+	values = make([]any, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		values = append(values, rv.Index(i).Interface())
+	}
+	return
+}
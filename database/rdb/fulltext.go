@@ -0,0 +1,180 @@
+package rdb
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FullText needs the index created by FullTextIndex on SQLite and MySQL.
+func FullText(query string, fields ...string) Condition {
+	query = strings.TrimSpace(query)
+	return func(db *gorm.DB) *gorm.DB {
+		if query == "" || len(fields) == 0 {
+			return db
+		}
+		columns := make([]any, 0, len(fields))
+		for _, field := range fields {
+			col, ok := column(db, field)
+			if !ok {
+				return db
+			}
+			columns = append(columns, col)
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(columns)), ",")
+		switch dialect(db) {
+		case driverPostgres:
+			document := "to_tsvector('simple'," + strings.TrimSuffix(strings.Repeat("coalesce(?,'') || ' ' || ", len(columns)), " || ' ' || ") + ")"
+			vars := append(columns, query)
+			db = db.Where(document+" @@ plainto_tsquery('simple',?)", vars...)
+			db = relevance(db, true, "ts_rank("+document+",plainto_tsquery('simple',?))", vars...)
+		case driverSqlite:
+			table := tableOf(db)
+			if table == "" {
+				_ = db.AddError(fmt.Errorf("full text search needs a model or table"))
+				return db
+			}
+			fts := clause.Table{Name: table + ftsTableSuffix}
+			match := ftsQuery(fields, query)
+			db = db.Where("?.rowid IN (SELECT rowid FROM ? WHERE ? MATCH ?)", clause.Table{Name: table}, fts, fts, match)
+			db = relevance(db, false, "(SELECT bm25(?) FROM ? WHERE ? MATCH ? AND ?.rowid = ?.rowid)", fts, fts, fts, match, fts, clause.Table{Name: table})
+		default:
+			match := "MATCH(" + placeholders + ") AGAINST(? IN NATURAL LANGUAGE MODE)"
+			vars := append(columns, query)
+			db = relevance(db.Where(match, vars...), true, match, vars...)
+		}
+		return db
+	}
+}
+
+func (ins *Instance) FullTextIndex(table Data, fields ...string) (err error) {
+	db := ins.DB()
+	stmt := &gorm.Statement{DB: db}
+	if err = stmt.Parse(table); err != nil {
+		return
+	}
+	if len(fields) == 0 {
+		return fmt.Errorf("full text index of %s needs fields", stmt.Table)
+	}
+	columns := make([]string, 0, len(fields))
+	for _, name := range fields {
+		field := stmt.Schema.LookUpField(name)
+		if field == nil || field.DBName == "" {
+			return fmt.Errorf("field %s not found in %s", name, stmt.Table)
+		}
+		columns = append(columns, stmt.Quote(field.DBName))
+	}
+	name, index := stmt.Quote(stmt.Table), stmt.Table+ftsIndexSuffix
+	var statements []string
+	switch dialect(db) {
+	case driverPostgres:
+		document := "coalesce(" + strings.Join(columns, ",'') || ' ' || coalesce(") + ",'')"
+		statements = append(statements, fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s USING GIN (to_tsvector('simple',%s))", stmt.Quote(index), name, document))
+	case driverSqlite:
+		fts := stmt.Quote(stmt.Table + ftsTableSuffix)
+		list := strings.Join(columns, ",")
+		values := func(prefix string) string {
+			return prefix + strings.Join(columns, ","+prefix)
+		}
+		insert := fmt.Sprintf("INSERT INTO %s(rowid,%s) VALUES (new.rowid,%s);", fts, list, values("new."))
+		remove := fmt.Sprintf("INSERT INTO %s(%s,rowid,%s) VALUES ('delete',old.rowid,%s);", fts, fts, list, values("old."))
+		statements = append(statements,
+			fmt.Sprintf("CREATE VIRTUAL TABLE IF NOT EXISTS %s USING fts5(%s,content='%s')", fts, list, strings.ReplaceAll(stmt.Table, "'", "''")),
+			fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %s AFTER INSERT ON %s BEGIN %s END", stmt.Quote(index+"_ai"), name, insert),
+			fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %s AFTER DELETE ON %s BEGIN %s END", stmt.Quote(index+"_ad"), name, remove),
+			fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %s AFTER UPDATE ON %s BEGIN %s %s END", stmt.Quote(index+"_au"), name, remove, insert),
+			fmt.Sprintf("INSERT INTO %s(%s) VALUES ('rebuild')", fts, fts),
+		)
+	default:
+		if db.Migrator().HasIndex(stmt.Table, index) {
+			return
+		}
+		statements = append(statements, fmt.Sprintf("CREATE FULLTEXT INDEX %s ON %s (%s)", stmt.Quote(index), name, strings.Join(columns, ",")))
+	}
+	for _, sql := range statements {
+		if err = db.Exec(sql).Error; err != nil {
+			return fmt.Errorf("create full text index of %s failed :%w", stmt.Table, err)
+		}
+	}
+	return
+}
+
+func relevance(db *gorm.DB, desc bool, sql string, vars ...any) *gorm.DB {
+	var exprs []clause.Expr
+	if v, ok := db.Get(relevanceKey); ok {
+		exprs, _ = v.([]clause.Expr)
+	}
+	name := relevanceMarker + strconv.Itoa(len(exprs))
+	exprs = append(exprs[:len(exprs):len(exprs)], clause.Expr{SQL: sql, Vars: vars, WithoutParentheses: true})
+	return db.Set(relevanceKey, exprs).Order(clause.OrderByColumn{Column: clause.Column{Name: name, Raw: true}, Desc: desc})
+}
+
+func orderByRelevance(db *gorm.DB) {
+	v, ok := db.Get(relevanceKey)
+	if !ok || db.Error != nil {
+		return
+	}
+	exprs, _ := v.([]clause.Expr)
+	c := db.Statement.Clauses["ORDER BY"]
+	orderBy, ok := c.Expression.(clause.OrderBy)
+	if !ok || orderBy.Expression != nil {
+		return
+	}
+	items := make([]clause.Expression, 0, len(orderBy.Columns))
+	for _, col := range orderBy.Columns {
+		expr := clause.Expression(clause.Expr{SQL: "?", Vars: []any{col.Column}})
+		if idx, found := strings.CutPrefix(col.Column.Name, relevanceMarker); found && col.Column.Raw {
+			if i, err := strconv.Atoi(idx); err == nil && i < len(exprs) {
+				expr = exprs[i]
+			}
+		}
+		if col.Desc {
+			expr = clause.Expr{SQL: "? DESC", Vars: []any{expr}}
+		}
+		items = append(items, expr)
+	}
+	c.Expression = clause.OrderBy{Expression: clause.CommaExpression{Exprs: items}}
+	db.Statement.Clauses["ORDER BY"] = c
+}
+
+func registerRelevance(db *gorm.DB) error {
+	return errors.Join(
+		db.Callback().Query().Before("gorm:query").Register(relevanceCallbackName, orderByRelevance),
+		db.Callback().Row().Before("gorm:row").Register(relevanceCallbackName, orderByRelevance),
+	)
+}
+
+func ftsQuery(fields []string, query string) string {
+	var builder strings.Builder
+	builder.WriteString("{")
+	for i, field := range fields {
+		if i > 0 {
+			builder.WriteByte(' ')
+		}
+		if idx := strings.LastIndexByte(field, '.'); idx >= 0 {
+			field = field[idx+1:]
+		}
+		builder.WriteString(field)
+	}
+	builder.WriteString("} : (")
+	for i, term := range strings.Fields(query) {
+		if i > 0 {
+			builder.WriteByte(' ')
+		}
+		builder.WriteString(`"` + strings.ReplaceAll(term, `"`, `""`) + `"`)
+	}
+	builder.WriteString(")")
+	return builder.String()
+}
+
+const (
+	ftsTableSuffix        = "_fts"
+	ftsIndexSuffix        = "_fulltext"
+	relevanceKey          = "rdb:relevance"
+	relevanceMarker       = "rdb:relevance:"
+	relevanceCallbackName = "rdb:relevance"
+)
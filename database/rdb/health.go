@@ -0,0 +1,122 @@
+package rdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Health struct {
+	Name     string        `json:"name"`
+	Healthy  bool          `json:"healthy"`
+	Latency  time.Duration `json:"latency"`
+	Error    string        `json:"error,omitempty"`
+	Replicas []*Health     `json:"replicas,omitempty"`
+}
+
+func HealthCheck(ctx context.Context) (result map[string]*Health) {
+	lock.RLock()
+	instances := make([]*Instance, 0, len(instanceMap))
+	for _, ins := range instanceMap {
+		instances = append(instances, ins)
+	}
+	lock.RUnlock()
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	result = make(map[string]*Health, len(instances))
+	for _, ins := range instances {
+		wg.Add(1)
+		go func(ins *Instance) {
+			defer wg.Done()
+			health := ins.Check(ctx)
+			mu.Lock()
+			result[ins.Name()] = health
+			mu.Unlock()
+		}(ins)
+	}
+	wg.Wait()
+	return
+}
+
+func (ins *Instance) Ping(ctx context.Context) (err error) {
+	_, err = ping(ctx, ins.db)
+	return
+}
+
+func (ins *Instance) Check(ctx context.Context) (health *Health) {
+	health = check(ctx, ins.name, ins.db)
+	ins.healthy.Store(health.Healthy)
+	if ins.resolver != nil {
+		for i, rep := range ins.resolver.replicas {
+			replicaHealth := check(ctx, fmt.Sprintf("%s/replica[%d]", ins.name, i), rep.db)
+			rep.healthy.Store(replicaHealth.Healthy)
+			health.Replicas = append(health.Replicas, replicaHealth)
+		}
+	}
+	return
+}
+
+func (ins *Instance) Healthy() bool {
+	return ins.healthy.Load()
+}
+
+func (ins *Instance) Stats() (stats sql.DBStats) {
+	if sqlDB, err := ins.db.DB(); err == nil {
+		stats = sqlDB.Stats()
+	}
+	return
+}
+
+func (ins *Instance) ReplicaStats() (stats []sql.DBStats) {
+	if ins.resolver != nil {
+		stats = make([]sql.DBStats, 0, len(ins.resolver.replicas))
+		for _, rep := range ins.resolver.replicas {
+			var stat sql.DBStats
+			if sqlDB, err := rep.db.DB(); err == nil {
+				stat = sqlDB.Stats()
+			}
+			stats = append(stats, stat)
+		}
+	}
+	return
+}
+
+func (ins *Instance) probe(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ins.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			ins.Check(ctx)
+			cancel()
+		}
+	}
+}
+
+func check(ctx context.Context, name string, db *gorm.DB) (health *Health) {
+	latency, err := ping(ctx, db)
+	health = &Health{Name: name, Healthy: err == nil, Latency: latency}
+	if err != nil {
+		health.Error = err.Error()
+	}
+	return
+}
+
+func ping(ctx context.Context, db *gorm.DB) (latency time.Duration, err error) {
+	var sqlDB *sql.DB
+	if sqlDB, err = db.DB(); err == nil {
+		start := time.Now()
+		err = sqlDB.PingContext(ctx)
+		latency = time.Since(start)
+	}
+	return
+}
@@ -0,0 +1,143 @@
+package rdb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	jsonPathPattern    = regexp.MustCompile(`^\$((\.[A-Za-z_][A-Za-z0-9_]*)|(\[[0-9]+\]))*$`)
+	jsonPathElement    = regexp.MustCompile(`\.([A-Za-z_][A-Za-z0-9_]*)|\[([0-9]+)\]`)
+	invalidJsonPathErr = errors.New("invalid json path")
+)
+
+func JsonContains(field string, value any) Condition {
+	return func(db *gorm.DB) *gorm.DB {
+		if field == "" || value == nil {
+			return db
+		}
+		if col, ok := column(db, field); ok {
+			switch dialect(db) {
+			case driverPostgres:
+				array, err := json.Marshal([]any{value})
+				if err != nil {
+					_ = db.AddError(fmt.Errorf("json contains value of %s :%w", field, err))
+					return db
+				}
+				db = db.Where("?::jsonb @> ?::jsonb", col, string(array))
+			case driverSqlite:
+				db = db.Where("EXISTS (SELECT 1 FROM json_each(?) WHERE json_each.value = ?)", col, value)
+			default:
+				db = db.Where("JSON_CONTAINS(?,JSON_ARRAY(?))", col, value)
+			}
+		}
+		return db
+	}
+}
+
+func JsonSearch(field string, value any) Condition {
+	return func(db *gorm.DB) *gorm.DB {
+		if field == "" || value == nil {
+			return db
+		}
+		if col, ok := column(db, field); ok {
+			switch dialect(db) {
+			case driverPostgres:
+				db = db.Where("EXISTS (SELECT 1 FROM jsonb_path_query(?::jsonb,'strict $.**') AS t(v) WHERE jsonb_typeof(t.v)='string' AND t.v#>>'{}' LIKE ?)", col, value)
+			case driverSqlite:
+				db = db.Where("EXISTS (SELECT 1 FROM json_tree(?) WHERE json_tree.type = 'text' AND json_tree.value LIKE ?)", col, value)
+			default:
+				db = db.Where("JSON_SEARCH(?,'all',?) IS NOT NULL", col, value)
+			}
+		}
+		return db
+	}
+}
+
+func JsonPathEqual(field, path string, value any) Condition {
+	return func(db *gorm.DB) *gorm.DB {
+		col, ok := jsonColumn(db, field, path)
+		if !ok {
+			return db
+		}
+		switch dialect(db) {
+		case driverPostgres:
+			db = db.Where("?::jsonb #>> ?::text[] = ?", col, postgresJsonPath(path), fmt.Sprint(value))
+		case driverSqlite:
+			db = db.Where("json_extract(?,?) = ?", col, path, value)
+		default:
+			db = db.Where("JSON_EXTRACT(?,?) = ?", col, path, value)
+		}
+		return db
+	}
+}
+
+func JsonHasKey(field, path string) Condition {
+	return func(db *gorm.DB) *gorm.DB {
+		col, ok := jsonColumn(db, field, path)
+		if !ok {
+			return db
+		}
+		switch dialect(db) {
+		case driverPostgres:
+			db = db.Where("?::jsonb #> ?::text[] IS NOT NULL", col, postgresJsonPath(path))
+		case driverSqlite:
+			db = db.Where("json_type(?,?) IS NOT NULL", col, path)
+		default:
+			db = db.Where("JSON_CONTAINS_PATH(?,'one',?)", col, path)
+		}
+		return db
+	}
+}
+
+func JsonArrayLength(field, path string, op OpType, length int) Condition {
+	return func(db *gorm.DB) *gorm.DB {
+		if path == "" {
+			path = "$"
+		}
+		col, ok := jsonColumn(db, field, path)
+		if !ok {
+			return db
+		}
+		switch op {
+		case LT, GT, LTE, GTE, "=", "!=":
+		default:
+			_ = db.AddError(fmt.Errorf("unSupport json array length op %s", op))
+			return db
+		}
+		switch dialect(db) {
+		case driverPostgres:
+			db = db.Where(fmt.Sprintf("jsonb_array_length(?::jsonb #> ?::text[]) %s ?", op), col, postgresJsonPath(path), length)
+		case driverSqlite:
+			db = db.Where(fmt.Sprintf("json_array_length(?,?) %s ?", op), col, path, length)
+		default:
+			db = db.Where(fmt.Sprintf("JSON_LENGTH(?,?) %s ?", op), col, path, length)
+		}
+		return db
+	}
+}
+
+func jsonColumn(db *gorm.DB, field, path string) (col clause.Column, ok bool) {
+	if field == "" {
+		return
+	}
+	if !jsonPathPattern.MatchString(path) {
+		_ = db.AddError(fmt.Errorf("%w %q", invalidJsonPathErr, path))
+		return
+	}
+	return column(db, field)
+}
+
+func postgresJsonPath(path string) string {
+	var elements []string
+	for _, match := range jsonPathElement.FindAllStringSubmatch(path, -1) {
+		elements = append(elements, match[1]+match[2])
+	}
+	return "{" + strings.Join(elements, ",") + "}"
+}
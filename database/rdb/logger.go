@@ -0,0 +1,119 @@
+package rdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/basebytes/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var sink atomic.Pointer[slog.Logger]
+
+func SetLogger(l *slog.Logger) {
+	sink.Store(l)
+}
+
+type LoggerConfig struct {
+	Level                string          `json:"level,omitempty"`
+	SlowThreshold        *types.Duration `json:"slowThreshold,omitempty"`
+	IgnoreRecordNotFound bool            `json:"ignoreRecordNotFound,omitempty"`
+}
+
+func (c *LoggerConfig) Init() (err error) {
+	c.Level = strings.ToLower(c.Level)
+	if _, ok := logLevels[c.Level]; !ok {
+		err = fmt.Errorf("unSupport logger level %s", c.Level)
+	}
+	return
+}
+
+func (c *LoggerConfig) clone() *LoggerConfig {
+	if c == nil {
+		return nil
+	}
+	cfg := *c
+	cfg.SlowThreshold = cloneDuration(c.SlowThreshold)
+	return &cfg
+}
+
+func newLogger(name string, cfg *LoggerConfig) logger.Interface {
+	l := &sqlLogger{name: name, level: logger.Silent}
+	if cfg != nil {
+		l.level = logLevels[strings.ToLower(cfg.Level)]
+		l.slowThreshold = duration(cfg.SlowThreshold)
+		l.ignoreRecordNotFound = cfg.IgnoreRecordNotFound
+	}
+	return l
+}
+
+type sqlLogger struct {
+	name                 string
+	level                logger.LogLevel
+	slowThreshold        time.Duration
+	ignoreRecordNotFound bool
+}
+
+func (l *sqlLogger) LogMode(level logger.LogLevel) logger.Interface {
+	newLogger := *l
+	newLogger.level = level
+	return &newLogger
+}
+
+func (l *sqlLogger) Info(ctx context.Context, msg string, data ...any) {
+	if l.level >= logger.Info {
+		l.log(ctx, slog.LevelInfo, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *sqlLogger) Warn(ctx context.Context, msg string, data ...any) {
+	if l.level >= logger.Warn {
+		l.log(ctx, slog.LevelWarn, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *sqlLogger) Error(ctx context.Context, msg string, data ...any) {
+	if l.level >= logger.Error {
+		l.log(ctx, slog.LevelError, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *sqlLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && l.level >= logger.Error && (!l.ignoreRecordNotFound || !errors.Is(err, gorm.ErrRecordNotFound)):
+		sql, rows := fc()
+		l.log(ctx, slog.LevelError, "sql error", slog.String("sql", sql), slog.Int64("rows", rows), slog.Duration("elapsed", elapsed), slog.String("error", err.Error()))
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= logger.Warn:
+		sql, rows := fc()
+		l.log(ctx, slog.LevelWarn, "slow sql", slog.String("sql", sql), slog.Int64("rows", rows), slog.Duration("elapsed", elapsed), slog.Duration("threshold", l.slowThreshold))
+	case l.level >= logger.Info:
+		sql, rows := fc()
+		l.log(ctx, slog.LevelInfo, "sql", slog.String("sql", sql), slog.Int64("rows", rows), slog.Duration("elapsed", elapsed))
+	}
+}
+
+func (l *sqlLogger) log(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+	out := sink.Load()
+	if out == nil {
+		out = slog.Default()
+	}
+	out.LogAttrs(ctx, level, msg, append([]slog.Attr{slog.String("instance", l.name)}, attrs...)...)
+}
+
+var logLevels = map[string]logger.LogLevel{
+	"":       logger.Silent,
+	"silent": logger.Silent,
+	"error":  logger.Error,
+	"warn":   logger.Warn,
+	"info":   logger.Info,
+}
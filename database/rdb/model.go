@@ -1,7 +1,11 @@
 package rdb
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"sync"
 	"sync/atomic"
 
 	"gorm.io/gorm"
@@ -14,10 +18,21 @@ type Data interface {
 }
 
 type Instance struct {
-	name  string
-	debug atomic.Bool
-	db    *gorm.DB
-	cfg   *Config
+	*connection
+	ctx context.Context
+	tx  *gorm.DB
+}
+
+type connection struct {
+	name      string
+	debug     atomic.Bool
+	db        *gorm.DB
+	cfg       *Config
+	resolver  *resolver
+	cache     *queryCache
+	healthy   atomic.Bool
+	stop      chan struct{}
+	closeOnce sync.Once
 }
 
 func NewInstance(name string, cfg *Config) (ins *Instance, err error) {
@@ -25,17 +40,79 @@ func NewInstance(name string, cfg *Config) (ins *Instance, err error) {
 		err = fmt.Errorf("database [%s] config not found", name)
 		return
 	}
-	ins = &Instance{name: name, cfg: cfg}
-	if ins.db, err = gorm.Open(cfg.Dial(), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
-	}); err == nil {
-		ins.debug.Store(false)
-		sqlDB, _ := ins.db.DB()
-		sqlDB.SetMaxIdleConns(ins.cfg.MaxIdleCons)
-		sqlDB.SetMaxOpenConns(ins.cfg.MaxOpenCons)
-	} else {
+	ins = &Instance{connection: &connection{name: name, cfg: cfg.clone()}}
+	if ins.db, err = open(cfg, newLogger(name, cfg.Logger)); err != nil {
+		err = fmt.Errorf("create database[%s] instance failed :%s", name, err)
+		return
+	}
+	ins.debug.Store(false)
+	ins.healthy.Store(true)
+	if err = registerRelevance(ins.db); err != nil {
+		closeDB(ins.db)
 		err = fmt.Errorf("create database[%s] instance failed :%s", name, err)
+		return
+	}
+	if len(cfg.Replicas) > 0 {
+		if ins.resolver, err = newResolver(ins.db, cfg); err == nil {
+			err = ins.resolver.register(ins.db)
+		}
+		if err != nil {
+			ins.resolver.close()
+			closeDB(ins.db)
+			err = fmt.Errorf("create database[%s] replicas failed :%s", name, err)
+			return
+		}
+	}
+	if cfg.Cache != nil {
+		ins.cache = newQueryCache(cfg.Cache)
+		if err = ins.cache.register(ins.db); err != nil {
+			ins.Close()
+			err = fmt.Errorf("create database[%s] cache failed :%s", name, err)
+			return
+		}
 	}
+	if interval := duration(cfg.HealthCheck); interval > 0 {
+		ins.stop = make(chan struct{})
+		go ins.probe(interval)
+	}
+	return
+}
+
+func open(cfg *Config, log logger.Interface) (db *gorm.DB, err error) {
+	dial := cfg.Dial()
+	if dial == nil {
+		err = fmt.Errorf("unSupport database driver %s", cfg.Driver)
+		return
+	}
+	if db, err = gorm.Open(dial, &gorm.Config{
+		Logger: log,
+	}); err == nil {
+		sqlDB, _ := db.DB()
+		sqlDB.SetMaxIdleConns(cfg.MaxIdleCons)
+		sqlDB.SetMaxOpenConns(cfg.MaxOpenCons)
+		sqlDB.SetConnMaxLifetime(duration(cfg.ConnMaxLifetime))
+		sqlDB.SetConnMaxIdleTime(duration(cfg.ConnMaxIdleTime))
+	}
+	return
+}
+
+func closeDB(db *gorm.DB) (err error) {
+	if db != nil {
+		var sqlDB *sql.DB
+		if sqlDB, err = db.DB(); err == nil {
+			err = sqlDB.Close()
+		}
+	}
+	return
+}
+
+func (ins *Instance) Close() (err error) {
+	ins.closeOnce.Do(func() {
+		if ins.stop != nil {
+			close(ins.stop)
+		}
+		err = errors.Join(ins.resolver.close(), closeDB(ins.db))
+	})
 	return
 }
 
@@ -47,6 +124,10 @@ func (ins *Instance) DBName() string {
 	return ins.cfg.DataBase
 }
 
+func (ins *Instance) Dialect() string {
+	return dialect(ins.db)
+}
+
 func (ins *Instance) EnableDebug() {
 	ins.debug.Store(true)
 }
@@ -55,12 +136,33 @@ func (ins *Instance) DisableDebug() {
 	ins.debug.Store(false)
 }
 
-func (ins *Instance) DB() *gorm.DB {
+func (ins *Instance) WithContext(ctx context.Context) *Instance {
+	return &Instance{connection: ins.connection, ctx: ctx, tx: txFromContext(ctx, ins.connection)}
+}
+
+func (ins *Instance) Context() context.Context {
+	if ins.ctx == nil {
+		return context.Background()
+	}
+	return ins.ctx
+}
+
+func (ins *Instance) DB() (db *gorm.DB) {
+	db = ins.db
+	if ins.tx != nil {
+		db = ins.tx
+	}
+	if ins.ctx != nil {
+		db = db.WithContext(ins.ctx)
+	}
 	if ins.debug.Load() {
-		return ins.db.Debug()
-	} else {
-		return ins.db
+		db = db.Debug()
 	}
+	return
+}
+
+func (ins *Instance) primary() *gorm.DB {
+	return ForcePrimary()(ins.DB())
 }
 
 func (ins *Instance) Create(table any) *gorm.DB {
@@ -68,7 +170,7 @@ func (ins *Instance) Create(table any) *gorm.DB {
 }
 
 func (ins *Instance) FirstOrCreate(data Data, condition ...Condition) *gorm.DB {
-	return ins.DB().Model(data).Scopes(condition...).FirstOrCreate(data)
+	return ins.primary().Model(data).Scopes(condition...).FirstOrCreate(data)
 }
 
 func (ins *Instance) CreateIgnoreConflicts(conflicts []string, values any) *gorm.DB {
@@ -91,7 +193,10 @@ func (ins *Instance) AssociationUpdatesNotEmpty(table Data, name string, values
 }
 
 func (ins *Instance) UpdatesNotEmpty(table Data) *gorm.DB {
-	return ins.DB().Updates(table).First(table)
+	if db := ins.versioned(ins.primary().Model(table), table, table, (*gorm.DB).Updates); db.Error != nil {
+		return db
+	}
+	return ins.primary().First(table)
 }
 
 func (ins *Instance) UpdatesWithCondition(table Data, values any, condition ...Condition) *gorm.DB {
@@ -99,7 +204,7 @@ func (ins *Instance) UpdatesWithCondition(table Data, values any, condition ...C
 }
 
 func (ins *Instance) UpdatesByCondition(table Data, condition ...Condition) *gorm.DB {
-	return ins.DB().Model(table).Scopes(condition...).Updates(table)
+	return ins.versioned(ins.DB().Model(table).Scopes(condition...), table, table, (*gorm.DB).Updates)
 }
 
 func (ins *Instance) UpdateColumn(table Data, column string, value any) *gorm.DB {
@@ -107,7 +212,19 @@ func (ins *Instance) UpdateColumn(table Data, column string, value any) *gorm.DB
 }
 
 func (ins *Instance) UpdateColumns(table Data, values any) *gorm.DB {
-	return ins.DB().Model(table).UpdateColumns(values)
+	return ins.versioned(ins.DB().Model(table), table, values, (*gorm.DB).UpdateColumns)
+}
+
+func (ins *Instance) versioned(db *gorm.DB, table Data, values any, update updateFunc) *gorm.DB {
+	v, err := newVersion(db, table)
+	switch {
+	case err != nil:
+		db.AddError(err)
+		return db
+	case v == nil:
+		return update(db, values)
+	}
+	return v.update(db, values, update)
 }
 
 func (ins *Instance) UpdateColumnsById(table Data, columns ...string) *gorm.DB {
@@ -126,7 +243,9 @@ func (ins *Instance) Upsert(conflicts, updates []string, values any) *gorm.DB {
 }
 
 func (ins *Instance) FindById(table Data, id any) *gorm.DB {
-	return ins.DB().First(table, id)
+	return ins.cached(table, table, func(db *gorm.DB) *gorm.DB {
+		return db.First(table, id)
+	})
 }
 
 func (ins *Instance) FindByCondition(table Data, result any) *gorm.DB {
@@ -134,11 +253,15 @@ func (ins *Instance) FindByCondition(table Data, result any) *gorm.DB {
 }
 
 func (ins *Instance) FindFirstByCondition(table Data) *gorm.DB {
-	return ins.DB().Where(table).First(table)
+	return ins.cached(table, table, func(db *gorm.DB) *gorm.DB {
+		return db.Where(table).First(table)
+	})
 }
 
 func (ins *Instance) GetData(table Data, result any, conditions ...Condition) *gorm.DB {
-	return ins.DB().Debug().Model(table).Where(table).Scopes(conditions...).Find(result)
+	return ins.cached(table, result, func(db *gorm.DB) *gorm.DB {
+		return db.Model(table).Where(table).Scopes(conditions...).Find(result)
+	})
 }
 
 func (ins *Instance) SubQuery(table Data, conditions ...Condition) *gorm.DB {
@@ -159,41 +282,41 @@ func (ins *Instance) PageQuery(table Data, result any, page Condition, condition
 	return
 }
 
-func (ins *Instance) BatchUpdatesNotEmpty(table []Data) (err error) {
-	tx := ins.DB().Begin()
-	for _, t := range table {
-		if err = tx.Updates(t).First(t).Error; err != nil {
-			tx.Rollback()
-			return
-		}
-	}
-	return tx.Commit().Error
+func (ins *Instance) BatchUpdatesNotEmpty(table []Data) error {
+	return ins.batchUpdates(table, false)
+}
+
+func (ins *Instance) BatchUpdatesNotEmptyAndRefresh(table []Data) error {
+	return ins.batchUpdates(table, true)
 }
 
 func (ins *Instance) Raw(sql string, args ...any) *gorm.DB {
 	return ins.DB().Raw(sql, args...)
 }
 
-func (ins *Instance) Transaction(fc func(tx *gorm.DB) error) error {
-	return ins.DB().Transaction(fc)
+func (ins *Instance) Transaction(fc func(tx *gorm.DB) error, opts ...*sql.TxOptions) (err error) {
+	var pool gorm.ConnPool
+	err = ins.DB().Transaction(func(tx *gorm.DB) error {
+		pool = tx.Statement.ConnPool
+		return fc(tx)
+	}, opts...)
+	ins.committed(pool)
+	return
 }
 
 func (ins *Instance) OrClause(condition ...Condition) *gorm.DB {
 	return ins.DB().Scopes(condition...)
 }
 
-func newDryRun() *gorm.DB {
-	return &gorm.DB{
-		Config: &gorm.Config{
-			DryRun: true,
-		},
-		Statement: &gorm.Statement{
-			Clauses: map[string]clause.Clause{},
-		},
+func dialect(db *gorm.DB) (name string) {
+	if db.Dialector != nil {
+		name = db.Dialector.Name()
 	}
+	return
 }
 
 type Condition = func(db *gorm.DB) *gorm.DB
+type JoinType = clause.JoinType
 type OrderType = string
 type OpType = string
 type FuzzyType int8
@@ -203,6 +326,12 @@ const (
 	DESC OrderType = "DESC"
 )
 
+const (
+	InnerJoinType JoinType = clause.InnerJoin
+	LeftJoinType  JoinType = clause.LeftJoin
+	RightJoinType JoinType = clause.RightJoin
+)
+
 const (
 	LT  OpType = "<"
 	GT  OpType = ">"
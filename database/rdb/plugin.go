@@ -0,0 +1,83 @@
+package rdb
+
+import (
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+func (ins *Instance) Use(plugins ...gorm.Plugin) (err error) {
+	for _, plugin := range plugins {
+		if err = ins.db.Use(plugin); err != nil {
+			err = fmt.Errorf("database[%s] use plugin %s failed :%s", ins.name, plugin.Name(), err)
+			return
+		}
+	}
+	return
+}
+
+func Unscoped() Condition {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}
+}
+
+func lookUpColumn(stmt *gorm.Statement, column string) *schema.Field {
+	if stmt.Schema == nil || column == "" {
+		return nil
+	}
+	if field := stmt.Schema.LookUpField(column); field != nil && field.DBName != "" {
+		return field
+	}
+	return nil
+}
+
+func addWhere(stmt *gorm.Statement, marker string, exprs ...clause.Expression) {
+	if _, ok := stmt.Clauses[marker]; ok {
+		return
+	}
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok {
+			for _, expr := range where.Exprs {
+				if or, ok := expr.(clause.OrConditions); ok && len(or.Exprs) == 1 {
+					where.Exprs = []clause.Expression{clause.And(where.Exprs...)}
+					c.Expression = where
+					stmt.Clauses["WHERE"] = c
+					break
+				}
+			}
+		}
+	}
+	stmt.AddClause(clause.Where{Exprs: exprs})
+	stmt.Clauses[marker] = clause.Clause{}
+}
+
+func requireWhere(db *gorm.DB) bool {
+	stmt := db.Statement
+	if _, ok := stmt.Clauses["WHERE"]; !ok && stmt.Schema != nil {
+		addPrimaryWhere(stmt)
+	}
+	if _, ok := stmt.Clauses["WHERE"]; !ok && !db.AllowGlobalUpdate {
+		_ = db.AddError(gorm.ErrMissingWhereClause)
+		return false
+	}
+	return true
+}
+
+func addPrimaryWhere(stmt *gorm.Statement) {
+	_, queryValues := schema.GetIdentityFieldValuesMap(stmt.Context, stmt.ReflectValue, stmt.Schema.PrimaryFields)
+	column, values := schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
+	if len(values) > 0 {
+		stmt.AddClause(clause.Where{Exprs: []clause.Expression{clause.IN{Column: column, Values: values}}})
+	}
+	if stmt.ReflectValue.CanAddr() && stmt.Dest != stmt.Model && stmt.Model != nil {
+		_, queryValues = schema.GetIdentityFieldValuesMap(stmt.Context, reflect.ValueOf(stmt.Model), stmt.Schema.PrimaryFields)
+		column, values = schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
+		if len(values) > 0 {
+			stmt.AddClause(clause.Where{Exprs: []clause.Expression{clause.IN{Column: column, Values: values}}})
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package rdb
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type pluginItem struct {
+	Id         int64     `gorm:"column:id;primaryKey"`
+	TenantId   int64     `gorm:"column:tenant_id"`
+	Name       string    `gorm:"column:name"`
+	Status     int       `gorm:"column:status"`
+	CreateBy   string    `gorm:"column:create_by"`
+	UpdateBy   string    `gorm:"column:update_by"`
+	CreateTime time.Time `gorm:"column:create_time"`
+	UpdateTime time.Time `gorm:"column:update_time"`
+}
+
+func (*pluginItem) TableName() string {
+	return "plugin_item"
+}
+
+func newPluginInstance(t *testing.T, plugins ...gorm.Plugin) *Instance {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "plugin.db")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &Config{Driver: driverSqlite, DataBase: path}
+	if err := cfg.Init(); err != nil {
+		t.Fatal(err)
+	}
+	ins, err := NewInstance(t.Name(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ins.Close() })
+	if err = ins.DB().Exec("CREATE TABLE plugin_item (id INTEGER PRIMARY KEY, tenant_id INTEGER, name TEXT, status INTEGER DEFAULT 0, create_by TEXT, update_by TEXT, create_time DATETIME, update_time DATETIME)").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err = ins.Use(plugins...); err != nil {
+		t.Fatal(err)
+	}
+	return ins
+}
+
+func seedPluginItems(t *testing.T, ins *Instance, items ...*pluginItem) {
+	t.Helper()
+	for _, item := range items {
+		if err := ins.DB().Exec("INSERT INTO plugin_item (id, tenant_id, name, status) VALUES (?, ?, ?, 0)", item.Id, item.TenantId, item.Name).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func pluginStatus(t *testing.T, ins *Instance, id int64) (status int, name string) {
+	t.Helper()
+	if err := ins.DB().Raw("SELECT status, name FROM plugin_item WHERE id = ?", id).Row().Scan(&status, &name); err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func TestSoftDelete(t *testing.T) {
+	ins := newPluginInstance(t, &SoftDelete{})
+	seedPluginItems(t, ins, &pluginItem{Id: 1, Name: "a"}, &pluginItem{Id: 2, Name: "b"}, &pluginItem{Id: 3, Name: "c"})
+
+	if err := ins.DeleteByCondition(&pluginItem{}).Error; !errors.Is(err, gorm.ErrMissingWhereClause) {
+		t.Fatalf("delete without conditions: got %v, want %v", err, gorm.ErrMissingWhereClause)
+	}
+	if err := ins.UpdateColumns(&pluginItem{}, map[string]any{"name": "x"}).Error; !errors.Is(err, gorm.ErrMissingWhereClause) {
+		t.Fatalf("update without conditions: got %v, want %v", err, gorm.ErrMissingWhereClause)
+	}
+	for id := int64(1); id <= 3; id++ {
+		if status, name := pluginStatus(t, ins, id); status != 0 || name == "x" {
+			t.Fatalf("row %d changed by rejected write: status=%d name=%s", id, status, name)
+		}
+	}
+
+	if res := ins.DeleteByCondition(&pluginItem{}, Equal("name", "b")); res.Error != nil || res.RowsAffected != 1 {
+		t.Fatalf("delete by condition: rows=%d err=%v", res.RowsAffected, res.Error)
+	}
+	if res := ins.DeleteByCondition(&pluginItem{Id: 3}); res.Error != nil || res.RowsAffected != 1 {
+		t.Fatalf("delete by primary key: rows=%d err=%v", res.RowsAffected, res.Error)
+	}
+	if status, _ := pluginStatus(t, ins, 2); status != defaultSoftDeleteValue {
+		t.Fatalf("row 2 status = %d, want %d", status, defaultSoftDeleteValue)
+	}
+
+	if count, err := ins.Count(&pluginItem{}); err != nil || count != 1 {
+		t.Fatalf("scoped count = %d, %v, want 1", count, err)
+	}
+	if count, err := ins.Count(&pluginItem{}, Unscoped()); err != nil || count != 3 {
+		t.Fatalf("unscoped count = %d, %v, want 3", count, err)
+	}
+	if err := ins.FindById(&pluginItem{}, 2).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("find deleted row: got %v, want %v", err, gorm.ErrRecordNotFound)
+	}
+}
+
+func TestTenant(t *testing.T) {
+	ins := newPluginInstance(t, &Tenant{})
+	seedPluginItems(t, ins, &pluginItem{Id: 1, TenantId: 1, Name: "a"}, &pluginItem{Id: 2, TenantId: 2, Name: "b"})
+	tenant1 := ins.WithContext(WithTenant(context.Background(), int64(1)))
+
+	if err := ins.FindById(&pluginItem{}, 1).Error; !errors.Is(err, missingTenantErr) {
+		t.Fatalf("query without tenant: got %v, want %v", err, missingTenantErr)
+	}
+
+	var items []*pluginItem
+	if err := tenant1.GetData(&pluginItem{}, &items); err != nil && err.Error != nil {
+		t.Fatal(err.Error)
+	}
+	if len(items) != 1 || items[0].Id != 1 {
+		t.Fatalf("tenant 1 sees %d rows, want only row 1", len(items))
+	}
+
+	if err := tenant1.DB().Model(&pluginItem{}).Updates(map[string]any{"name": "x"}).Error; !errors.Is(err, gorm.ErrMissingWhereClause) {
+		t.Fatalf("update without conditions: got %v, want %v", err, gorm.ErrMissingWhereClause)
+	}
+	if err := tenant1.BatchUpdatesNotEmpty([]Data{&pluginItem{Id: 2, Name: "stolen"}}); err != nil {
+		t.Fatal(err)
+	}
+	if res := tenant1.DeleteByCondition(&pluginItem{Id: 2}); res.Error != nil || res.RowsAffected != 0 {
+		t.Fatalf("delete other tenant row: rows=%d err=%v", res.RowsAffected, res.Error)
+	}
+	if _, name := pluginStatus(t, ins, 2); name != "b" {
+		t.Fatalf("tenant 1 rewrote tenant 2 row: name=%s", name)
+	}
+
+	if err := tenant1.BatchUpdatesNotEmpty([]Data{&pluginItem{Id: 1, Name: "mine"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, name := pluginStatus(t, ins, 1); name != "mine" {
+		t.Fatalf("tenant 1 row name = %s, want mine", name)
+	}
+
+	created := &pluginItem{Id: 3, Name: "c"}
+	if err := tenant1.Create(created).Error; err != nil {
+		t.Fatal(err)
+	}
+	if created.TenantId != 1 {
+		t.Fatalf("created tenant = %d, want 1", created.TenantId)
+	}
+}
+
+func TestAudit(t *testing.T) {
+	ins := newPluginInstance(t, &Audit{})
+	ctx := WithOperator(context.Background(), "alice")
+	item := &pluginItem{Id: 1, Name: "a"}
+	if err := ins.WithContext(ctx).Create(item).Error; err != nil {
+		t.Fatal(err)
+	}
+	if item.CreateBy != "alice" || item.UpdateBy != "alice" || item.CreateTime.IsZero() || item.UpdateTime.IsZero() {
+		t.Fatalf("create stamps not set: %+v", item)
+	}
+
+	ctx = WithOperator(context.Background(), "bob")
+	if err := ins.WithContext(ctx).BatchUpdatesNotEmpty([]Data{&pluginItem{Id: 1, Name: "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	stored := &pluginItem{}
+	if err := ins.FindById(stored, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored.CreateBy != "alice" || stored.UpdateBy != "bob" || stored.Name != "b" {
+		t.Fatalf("update stamps not set: %+v", stored)
+	}
+}
@@ -3,11 +3,13 @@ package rdb
 import (
 	"errors"
 	"sync"
+	"time"
 )
 
 var (
 	instanceMap map[string]*Instance
 	lock        sync.RWMutex
+	reloadLock  sync.Mutex
 	once        sync.Once
 )
 
@@ -23,17 +25,51 @@ func Init(configs map[string]*Config) {
 	})
 }
 
-func Reload(configs map[string]*Config) error {
+func Reload(configs map[string]*Config) (err error) {
 	if instanceCount() == 0 {
 		return uninitializedErr
 	}
-	instances, err := load(configs)
-	if err == nil {
-		lock.Lock()
-		defer lock.Unlock()
-		instanceMap = instances
+	reloadLock.Lock()
+	defer reloadLock.Unlock()
+	lock.RLock()
+	current := instanceMap
+	lock.RUnlock()
+	var (
+		instances = make(map[string]*Instance, len(configs))
+		created   = make(map[string]*Instance, len(configs))
+	)
+	for name, config := range configs {
+		if ins, ok := current[name]; ok && config != nil && ins.cfg.equal(config) {
+			instances[name] = ins
+			continue
+		}
+		var ins *Instance
+		if ins, err = NewInstance(name, config); err != nil {
+			closeAll(created)
+			return
+		}
+		instances[name], created[name] = ins, ins
+	}
+	lock.Lock()
+	instanceMap = instances
+	lock.Unlock()
+	for name, ins := range current {
+		if instances[name] != ins {
+			go retire(ins)
+		}
 	}
-	return err
+	return
+}
+
+func Close() (err error) {
+	reloadLock.Lock()
+	defer reloadLock.Unlock()
+	lock.Lock()
+	current := instanceMap
+	instanceMap = nil
+	once = sync.Once{}
+	lock.Unlock()
+	return closeAll(current)
 }
 
 func load(configs map[string]*Config) (instanceMap map[string]*Instance, err error) {
@@ -41,6 +77,8 @@ func load(configs map[string]*Config) (instanceMap map[string]*Instance, err err
 	for name, config := range configs {
 		var ins *Instance
 		if ins, err = NewInstance(name, config); err != nil {
+			closeAll(instanceMap)
+			instanceMap = nil
 			break
 		}
 		instanceMap[name] = ins
@@ -48,6 +86,19 @@ func load(configs map[string]*Config) (instanceMap map[string]*Instance, err err
 	return
 }
 
+func closeAll(instances map[string]*Instance) error {
+	errs := make([]error, 0, len(instances))
+	for _, ins := range instances {
+		errs = append(errs, ins.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func retire(ins *Instance) {
+	time.Sleep(retireDelay)
+	_ = ins.Close()
+}
+
 func GetConnection(name string) (ins *Instance, ok bool) {
 	lock.RLock()
 	defer lock.RUnlock()
@@ -79,3 +130,5 @@ func instanceCount() (count int) {
 }
 
 var uninitializedErr = errors.New("rdb instance uninitialized")
+
+const retireDelay = 10 * time.Second
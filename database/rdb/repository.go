@@ -0,0 +1,96 @@
+package rdb
+
+import (
+	"context"
+	"reflect"
+)
+
+type Repository[T Data] struct {
+	ins *Instance
+}
+
+func NewRepository[T Data](ins *Instance) *Repository[T] {
+	return &Repository[T]{ins: ins}
+}
+
+func (r *Repository[T]) Instance() *Instance {
+	return r.ins
+}
+
+func (r *Repository[T]) WithContext(ctx context.Context) *Repository[T] {
+	return &Repository[T]{ins: r.ins.WithContext(ctx)}
+}
+
+func (r *Repository[T]) Get(id any, conditions ...Condition) (item T, err error) {
+	item = newData[T]()
+	err = r.ins.DB().Model(item).Scopes(conditions...).First(item, id).Error
+	return
+}
+
+func (r *Repository[T]) First(conditions ...Condition) (item T, err error) {
+	item = newData[T]()
+	err = r.ins.DB().Model(item).Scopes(conditions...).First(item).Error
+	return
+}
+
+func (r *Repository[T]) List(conditions ...Condition) (items []T, err error) {
+	err = r.ins.DB().Model(newData[T]()).Scopes(conditions...).Find(&items).Error
+	return
+}
+
+func (r *Repository[T]) Page(page Condition, conditions ...Condition) (items []T, total int64, err error) {
+	total, err = r.ins.PageQuery(newData[T](), &items, page, conditions...)
+	return
+}
+
+func (r *Repository[T]) Cursor(keyset *Keyset, conditions ...Condition) (items []T, next string, total int64, err error) {
+	next, total, err = r.ins.CursorQuery(newData[T](), &items, keyset, conditions...)
+	return
+}
+
+func (r *Repository[T]) Each(fn func(item T) error, conditions ...Condition) error {
+	return r.ins.Each(newData[T](), func(row Data) error {
+		item, ok := row.(T)
+		if !ok {
+			item = reflect.ValueOf(row).Elem().Interface().(T)
+		}
+		return fn(item)
+	}, conditions...)
+}
+
+func (r *Repository[T]) FindInBatches(size int, cp *Checkpoint, fn func(items []T, cp *Checkpoint) error, conditions ...Condition) error {
+	var items []T
+	return r.ins.FindInBatches(newData[T](), &items, size, cp, func(cp *Checkpoint) error {
+		return fn(items, cp)
+	}, conditions...)
+}
+
+func (r *Repository[T]) Count(conditions ...Condition) (int64, error) {
+	return r.ins.Count(newData[T](), conditions...)
+}
+
+func (r *Repository[T]) Create(items ...T) (err error) {
+	if len(items) > 0 {
+		err = r.ins.Create(&items).Error
+	}
+	return
+}
+
+func (r *Repository[T]) Upsert(conflicts, updates []string, items ...T) (err error) {
+	if len(items) > 0 {
+		err = r.ins.Upsert(conflicts, updates, &items).Error
+	}
+	return
+}
+
+func (r *Repository[T]) Delete(conditions ...Condition) (rows int64, err error) {
+	db := r.ins.DeleteByCondition(newData[T](), conditions...)
+	return db.RowsAffected, db.Error
+}
+
+func newData[T Data]() (t T) {
+	if typ := reflect.TypeOf(t); typ != nil && typ.Kind() == reflect.Ptr {
+		t = reflect.New(typ.Elem()).Interface().(T)
+	}
+	return
+}
@@ -0,0 +1,93 @@
+package rdb
+
+import (
+	"math/rand"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+func ForcePrimary() Condition {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Set(forcePrimaryKey, true)
+	}
+}
+
+type resolver struct {
+	primary  gorm.ConnPool
+	replicas []*replica
+	policy   string
+	next     atomic.Uint64
+}
+
+type replica struct {
+	db      *gorm.DB
+	healthy atomic.Bool
+}
+
+func newResolver(primary *gorm.DB, cfg *Config) (r *resolver, err error) {
+	r = &resolver{primary: primary.ConnPool, policy: cfg.ReplicaPolicy}
+	for _, replicaCfg := range cfg.Replicas {
+		rep := &replica{}
+		if rep.db, err = open(replicaCfg, primary.Logger); err != nil {
+			break
+		}
+		rep.healthy.Store(true)
+		r.replicas = append(r.replicas, rep)
+	}
+	return
+}
+
+func (r *resolver) register(db *gorm.DB) (err error) {
+	if err = db.Callback().Query().Before("gorm:query").Register(resolverCallbackName, r.route); err == nil {
+		err = db.Callback().Row().Before("gorm:row").Register(resolverCallbackName, r.route)
+	}
+	return
+}
+
+func (r *resolver) route(db *gorm.DB) {
+	if db.Statement.ConnPool != r.primary {
+		return
+	}
+	if force, ok := db.Get(forcePrimaryKey); ok && force == true {
+		return
+	}
+	if rep := r.choose(); rep != nil {
+		db.Statement.ConnPool = rep.db.ConnPool
+	}
+}
+
+func (r *resolver) choose() (rep *replica) {
+	n := len(r.replicas)
+	if n == 0 {
+		return
+	}
+	var start int
+	if r.policy == ReplicaPolicyRandom {
+		start = rand.Intn(n)
+	} else {
+		start = int((r.next.Add(1) - 1) % uint64(n))
+	}
+	for i := 0; i < n; i++ {
+		if candidate := r.replicas[(start+i)%n]; candidate.healthy.Load() {
+			return candidate
+		}
+	}
+	return
+}
+
+func (r *resolver) close() (err error) {
+	if r != nil {
+		for _, rep := range r.replicas {
+			if e := closeDB(rep.db); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+	return
+}
+
+const (
+	forcePrimaryKey      = "rdb:force_primary"
+	resolverCallbackName = "rdb:resolver"
+)
@@ -0,0 +1,78 @@
+package rdb
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SoftDelete struct {
+	Column  string
+	Deleted any
+}
+
+func (p *SoftDelete) Name() string {
+	return softDeleteCallbackName
+}
+
+func (p *SoftDelete) Initialize(db *gorm.DB) error {
+	if p.Column == "" {
+		p.Column = defaultSoftDeleteColumn
+	}
+	if p.Deleted == nil {
+		p.Deleted = defaultSoftDeleteValue
+	}
+	cb := db.Callback()
+	return errors.Join(
+		cb.Query().Before("gorm:query").Register(softDeleteCallbackName, p.filter),
+		cb.Row().Before("gorm:row").Register(softDeleteCallbackName, p.filter),
+		cb.Update().Before("gorm:update").Register(softDeleteCallbackName, p.update),
+		cb.Delete().Before("gorm:delete").Register(softDeleteCallbackName, p.delete),
+	)
+}
+
+func (p *SoftDelete) filter(db *gorm.DB) {
+	stmt := db.Statement
+	if db.Error != nil || stmt.Unscoped {
+		return
+	}
+	if field := lookUpColumn(stmt, p.Column); field != nil {
+		column := clause.Column{Table: clause.CurrentTable, Name: field.DBName}
+		addWhere(stmt, softDeleteCallbackName, clause.Expr{SQL: "? <> ? OR ? IS NULL", Vars: []any{column, p.Deleted, column}})
+	}
+}
+
+func (p *SoftDelete) update(db *gorm.DB) {
+	stmt := db.Statement
+	if db.Error != nil || stmt.Unscoped || stmt.SQL.Len() > 0 || lookUpColumn(stmt, p.Column) == nil {
+		return
+	}
+	if requireWhere(db) {
+		p.filter(db)
+	}
+}
+
+func (p *SoftDelete) delete(db *gorm.DB) {
+	stmt := db.Statement
+	if db.Error != nil || stmt.Unscoped || stmt.SQL.Len() > 0 {
+		return
+	}
+	field := lookUpColumn(stmt, p.Column)
+	if field == nil {
+		return
+	}
+	if !requireWhere(db) {
+		return
+	}
+	stmt.AddClause(clause.Set{{Column: clause.Column{Name: field.DBName}, Value: p.Deleted}})
+	p.filter(db)
+	stmt.AddClauseIfNotExists(clause.Update{})
+	stmt.Build(db.Callback().Update().Clauses...)
+}
+
+const (
+	softDeleteCallbackName  = "rdb:soft_delete"
+	defaultSoftDeleteColumn = "status"
+	defaultSoftDeleteValue  = -1
+)
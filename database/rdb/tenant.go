@@ -0,0 +1,88 @@
+package rdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type tenantKey struct{}
+
+func WithTenant(ctx context.Context, tenant any) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+func TenantOf(ctx context.Context) (tenant any, ok bool) {
+	if ctx != nil {
+		tenant = ctx.Value(tenantKey{})
+	}
+	return tenant, tenant != nil
+}
+
+type Tenant struct {
+	Column string
+}
+
+func (p *Tenant) Name() string {
+	return tenantCallbackName
+}
+
+func (p *Tenant) Initialize(db *gorm.DB) error {
+	if p.Column == "" {
+		p.Column = defaultTenantColumn
+	}
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("*").Register(tenantCallbackName, p.create),
+		cb.Query().Before("*").Register(tenantCallbackName, p.filter),
+		cb.Row().Before("*").Register(tenantCallbackName, p.filter),
+		cb.Update().Before("*").Register(tenantCallbackName, p.write),
+		cb.Delete().Before("*").Register(tenantCallbackName, p.write),
+	)
+}
+
+func (p *Tenant) create(db *gorm.DB) {
+	if field, tenant, ok := p.tenant(db); ok {
+		db.Statement.SetColumn(field, tenant, true)
+	}
+}
+
+func (p *Tenant) filter(db *gorm.DB) {
+	if field, tenant, ok := p.tenant(db); ok {
+		addWhere(db.Statement, tenantCallbackName, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field}, Value: tenant})
+	}
+}
+
+func (p *Tenant) write(db *gorm.DB) {
+	if db.Statement.SQL.Len() > 0 {
+		return
+	}
+	if field, tenant, ok := p.tenant(db); ok && requireWhere(db) {
+		addWhere(db.Statement, tenantCallbackName, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field}, Value: tenant})
+	}
+}
+
+func (p *Tenant) tenant(db *gorm.DB) (column string, tenant any, ok bool) {
+	if db.Error != nil {
+		return
+	}
+	field := lookUpColumn(db.Statement, p.Column)
+	if field == nil {
+		return
+	}
+	if tenant, ok = TenantOf(db.Statement.Context); !ok {
+		db.AddError(fmt.Errorf("%w: table %s", missingTenantErr, db.Statement.Table))
+		return
+	}
+	return field.DBName, tenant, true
+}
+
+var missingTenantErr = errors.New("missing tenant in context")
+
+const (
+	tenantCallbackName  = "rdb:tenant"
+	defaultTenantColumn = "tenant_id"
+)
@@ -0,0 +1,42 @@
+package rdb
+
+import (
+	"context"
+	"database/sql"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct {
+	conn *connection
+}
+
+func (ins *Instance) Tx(fn func(tx *Instance) error, opts ...*sql.TxOptions) error {
+	return ins.Transaction(func(tx *gorm.DB) error {
+		ctx := context.WithValue(ins.Context(), txKey{ins.connection}, tx)
+		return fn(&Instance{connection: ins.connection, ctx: ctx, tx: tx})
+	}, opts...)
+}
+
+func (ins *Instance) TxContext(ctx context.Context, fn func(ctx context.Context) error, opts ...*sql.TxOptions) error {
+	return ins.WithContext(ctx).Tx(func(tx *Instance) error {
+		return fn(tx.Context())
+	}, opts...)
+}
+
+func (ins *Instance) InTx() bool {
+	return ins.tx != nil
+}
+
+func txFromContext(ctx context.Context, conn *connection) (tx *gorm.DB) {
+	if ctx != nil {
+		tx, _ = ctx.Value(txKey{conn}).(*gorm.DB)
+	}
+	return
+}
+
+func (ins *Instance) committed(pool gorm.ConnPool) {
+	if ins.cache != nil && ins.tx == nil && pool != nil {
+		ins.cache.commit(pool)
+	}
+}
@@ -0,0 +1,148 @@
+package rdb
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var ErrStaleWrite = errors.New("stale write: row was changed or removed concurrently")
+
+type Versioned interface {
+	Data
+	VersionColumn() string
+}
+
+type version struct {
+	field   *schema.Field
+	targets []versionTarget
+	current any
+	next    any
+}
+
+type versionTarget struct {
+	field *schema.Field
+	value reflect.Value
+}
+
+type updateFunc func(db *gorm.DB, values any) *gorm.DB
+
+func newVersion(db *gorm.DB, table Data) (v *version, err error) {
+	versioned, ok := table.(Versioned)
+	if !ok {
+		return
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err = stmt.Parse(table); err != nil {
+		return
+	}
+	field := stmt.Schema.LookUpField(versioned.VersionColumn())
+	if field == nil || field.DBName == "" {
+		err = fmt.Errorf("table %s version column %s not found", stmt.Schema.Table, versioned.VersionColumn())
+		return
+	}
+	target := reflect.Indirect(reflect.ValueOf(table))
+	v = &version{field: field, targets: []versionTarget{{field, target}}}
+	v.current, _ = field.ValueOf(db.Statement.Context, target)
+	v.next, err = nextVersion(db, field, v.current)
+	return
+}
+
+func nextVersion(db *gorm.DB, field *schema.Field, current any) (next any, err error) {
+	value := reflect.Indirect(reflect.ValueOf(current))
+	if field.IndirectFieldType == reflect.TypeOf(time.Time{}) {
+		precision := timePrecision(db, field)
+		now := db.NowFunc().Truncate(precision)
+		if value.IsValid() {
+			if last, ok := value.Interface().(time.Time); ok && !now.After(last) {
+				now = last.Truncate(precision).Add(precision)
+			}
+		}
+		return now, nil
+	}
+	switch field.IndirectFieldType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !value.IsValid() {
+			return int64(1), nil
+		}
+		return value.Int() + 1, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if !value.IsValid() {
+			return uint64(1), nil
+		}
+		return value.Uint() + 1, nil
+	}
+	err = fmt.Errorf("unsupported version column %s type %s", field.DBName, field.FieldType)
+	return
+}
+
+func timePrecision(db *gorm.DB, field *schema.Field) time.Duration {
+	precision := field.Precision
+	if precision <= 0 && dialect(db) != driverMysql {
+		precision = defaultTimePrecision
+	}
+	d := time.Second
+	for i := 0; i < precision && i < 9; i++ {
+		d /= 10
+	}
+	return d
+}
+
+func (v *version) update(db *gorm.DB, values any, update updateFunc) *gorm.DB {
+	values, err := v.assign(db, values)
+	if err != nil {
+		db.AddError(err)
+		return db
+	}
+	column := clause.Column{Table: clause.CurrentTable, Name: v.field.DBName}
+	db = update(db.Where(clause.Eq{Column: column, Value: v.current}), values)
+	if db.Error == nil && db.RowsAffected == 0 {
+		db.AddError(ErrStaleWrite)
+	}
+	value := v.next
+	if db.Error != nil {
+		value = v.current
+	}
+	for _, target := range v.targets {
+		_ = target.field.Set(db.Statement.Context, target.value, value)
+	}
+	return db
+}
+
+func (v *version) assign(db *gorm.DB, values any) (any, error) {
+	switch value := values.(type) {
+	case map[string]any:
+		assigned := make(map[string]any, len(value)+1)
+		for k, val := range value {
+			assigned[k] = val
+		}
+		assigned[v.field.DBName] = v.next
+		return assigned, nil
+	}
+	target := reflect.Indirect(reflect.ValueOf(values))
+	if target.Kind() != reflect.Struct || !target.CanAddr() {
+		return nil, fmt.Errorf("versioned update values must be a map[string]any or a struct pointer")
+	}
+	field := v.field
+	if target.Type() != field.Schema.ModelType {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(values); err != nil {
+			return nil, err
+		}
+		if field = stmt.Schema.LookUpField(v.field.DBName); field == nil {
+			return nil, fmt.Errorf("versioned update values must carry version column %s", v.field.DBName)
+		}
+		v.targets = append(v.targets, versionTarget{field, target})
+	}
+	if err := field.Set(db.Statement.Context, target, v.next); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+const defaultTimePrecision = 6